@@ -1,18 +1,18 @@
 package main
 
 import (
-	"crypto/md5"
-	"encoding/json"
 	"fmt"
+	"iter"
+	"slices"
 	"strings"
 )
 
-func minmax(a, b int) (int, int) {
-	if a < b {
-		return a, b
+func abs(a int) int {
+	if a < 0 {
+		return -a
 	}
 
-	return b, a
+	return a
 }
 
 //	given interface
@@ -47,36 +47,43 @@ type RasterImage interface {
 	GetPoints() []Point
 }
 
+// the size of the canvas comes from the points, so a stream is collected once here
+// instead of being rasterized twice, DrawPointsIn draws a stream without collecting it
 func DrawPoints(owner RasterImage) string {
-	maxX, maxY := 0, 0
 	points := owner.GetPoints()
 
-	//	setting maxX and maxY
-	for _, pixel := range points {
-		if pixel.X > maxX {
-			maxX = pixel.X
-		}
+	//	the canvas always starts at the origin unless something lies left of or above it
+	return drawPoints(slices.Values(points), BoundingBox(points).Include(Point{0, 0}))
+}
 
-		if pixel.Y > maxY {
-			maxY = pixel.Y
-		}
+// draws only the part of the image that falls inside the viewport
+func DrawPointsIn(owner RasterImage, viewport Viewport) string {
+	if c, ok := owner.(clippedRasterImage); ok {
+		return drawPoints(c.PointsIn(viewport), viewport)
 	}
+	return drawPoints(pointsOf(owner), viewport)
+}
 
-	maxX += 1
-	maxY += 1
+func drawPoints(points iter.Seq[Point], viewport Viewport) string {
+	if viewport.Empty() {
+		return ""
+	}
 
 	//	pre-allocate
-	data := make([][]rune, maxY)
-	for i := 0; i < maxY; i++ {
-		data[i] = make([]rune, maxX)
+	data := make([][]rune, viewport.Height)
+	for i := range data {
+		data[i] = make([]rune, viewport.Width)
 		for j := range data[i] {
 			data[i][j] = ' '
 		}
 	}
 
-	//	setting the points
-	for _, point := range points {
-		data[point.Y][point.X] = '*'
+	//	setting the points, relative to the top left corner of the viewport
+	for point := range points {
+		if !viewport.Contains(point) {
+			continue
+		}
+		data[point.Y-viewport.Y][point.X-viewport.X] = '*'
 	}
 
 	//	creating string
@@ -92,6 +99,7 @@ func DrawPoints(owner RasterImage) string {
 // adapter
 type vectorToRasterAdapter struct {
 	points []Point
+	cache  *PointCache
 }
 
 // implementing the interface
@@ -101,63 +109,86 @@ func (r *vectorToRasterAdapter) GetPoints() []Point {
 
 // conversion of data, we are using the cached version
 func (r *vectorToRasterAdapter) addLine(line Line) {
-	left, right := minmax(line.X1, line.X2)
-	top, bottom := minmax(line.Y1, line.Y2)
+	r.points = append(r.points, rasterizeLine(line)...)
+
+	fmt.Println("we have", len(r.points), "points")
+}
+
+// bresenham's line algorithm, works for every octant
+func rasterizeLine(line Line) []Point {
+	return slices.Collect(rasterizeLineSeq(line))
+}
 
-	dx := right - left
-	dy := line.Y2 - line.Y1
+// same as rasterizeLine but the points are only worked out as they are consumed
+func rasterizeLineSeq(line Line) iter.Seq[Point] {
+	return func(yield func(Point) bool) {
+		x1, y1 := line.X1, line.Y1
+		x2, y2 := line.X2, line.Y2
 
-	if dx == 0 {
-		for y := top; y <= bottom; y++ {
-			r.points = append(r.points, Point{left, y})
+		//	always walk from the same end so that a reversed line gives the exact same pixels
+		if x1 > x2 || (x1 == x2 && y1 > y2) {
+			x1, y1, x2, y2 = x2, y2, x1, y1
 		}
-	} else if dy == 0 {
-		for x := left; x <= right; x++ {
-			r.points = append(r.points, Point{x, top})
+
+		dx := abs(x2 - x1)
+		dy := -abs(y2 - y1)
+		sx, sy := 1, 1
+		if x1 > x2 {
+			sx = -1
+		}
+		if y1 > y2 {
+			sy = -1
 		}
-	}
 
-	fmt.Println("we have", len(r.points), "points")
+		err := dx + dy
+		for {
+			if !yield(Point{x1, y1}) {
+				return
+			}
+			if x1 == x2 && y1 == y2 {
+				return
+			}
+
+			e2 := 2 * err
+			if e2 >= dy {
+				err += dy
+				x1 += sx
+			}
+			if e2 <= dx {
+				err += dx
+				y1 += sy
+			}
+		}
+	}
 }
 
-// caching the adapter generated points so we dont have to do it again and again
-var pointCache map[[16]byte][]Point = map[[16]byte][]Point{}
-
+// uses the points of the line from the cache if the adapter has one
 func (r *vectorToRasterAdapter) addLineCached(line Line) {
-	hash := func(obj interface{}) [16]byte {
-		bytes, _ := json.Marshal(obj)
-		return md5.Sum(bytes)
+	if r.cache == nil {
+		r.addLine(line)
+		return
 	}
 
-	h := hash(line)
-	if pts, ok := pointCache[h]; ok {
+	if pts, ok := r.cache.Get(line); ok {
 		r.points = append(r.points, pts...)
 		return
 	}
 
-	left, right := minmax(line.X1, line.X2)
-	top, bottom := minmax(line.Y1, line.Y2)
-
-	dx := right - left
-	dy := line.Y2 - line.Y1
-
-	if dx == 0 {
-		for y := top; y <= bottom; y++ {
-			r.points = append(r.points, Point{left, y})
-		}
-	} else if dy == 0 {
-		for x := left; x <= right; x++ {
-			r.points = append(r.points, Point{x, top})
-		}
-	}
-
-	pointCache[h] = r.points
+	//	only the points of this line are cached, not everything the adapter has so far
+	pts := rasterizeLine(line)
+	r.cache.Put(line, pts)
+	r.points = append(r.points, pts...)
 	fmt.Println("we have", len(r.points), "points")
 }
 
 // converting the first interface into the required interface
 func VectorToRaster(vi *VectorImage) RasterImage {
-	adapter := vectorToRasterAdapter{}
+	return VectorToRasterCached(vi, nil)
+}
+
+// same as VectorToRaster but reuses the points of lines already present in the cache
+func VectorToRasterCached(vi *VectorImage, cache *PointCache) RasterImage {
+	adapter := vectorToRasterAdapter{cache: cache}
 	for _, line := range vi.Lines {
 		adapter.addLineCached(line)
 	}
@@ -169,14 +200,24 @@ func TestAdapter() {
 	//	first interface
 	rc := NewRectangle(30, 10)
 	rc2 := NewRectangle(30, 10)
+	cache := NewPointCache(64)
 
 	//	adapter
-	a := VectorToRaster(rc)
+	a := VectorToRasterCached(rc, cache)
 
 	//	requires second interface
 	fmt.Println(DrawPoints(a))
-	fmt.Println(DrawPoints(VectorToRaster(rc2)))
-
-	//	recomputation happens as the `VectorImage` is different
-	fmt.Println(DrawPoints(VectorToRaster(NewRectangle(30, 20))))
+	fmt.Println(DrawPoints(VectorToRasterCached(rc2, cache)))
+
+	//	recomputation happens only for the lines that are different
+	fmt.Println(DrawPoints(VectorToRasterCached(NewRectangle(30, 20), cache)))
+
+	TestPointCache()
+	TestSVG()
+	TestRasterToImage()
+	TestRasterToVector()
+	TestShapes()
+	TestViewport()
+	TestStreaming()
+	TestAdapterRegistry()
 }
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiagonalLines(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		lines  []Line
+		golden []string
+	}{
+		{"diagonal", []Line{{0, 0, 4, 4}}, []string{
+			"*    ",
+			" *   ",
+			"  *  ",
+			"   * ",
+			"    *",
+		}},
+		//	reversed endpoints should give the same pixels
+		{"reversed shallow", []Line{{6, 2, 0, 0}}, []string{
+			"**     ",
+			"  ***  ",
+			"     **",
+		}},
+		{"shallow", []Line{{0, 0, 6, 2}}, []string{
+			"**     ",
+			"  ***  ",
+			"     **",
+		}},
+		//	steep line going up and to the right
+		{"steep", []Line{{0, 4, 2, 0}}, []string{
+			"  *",
+			"  *",
+			" * ",
+			" * ",
+			"*  ",
+		}},
+		{"triangle", []Line{{0, 4, 4, 0}, {4, 0, 8, 4}, {0, 4, 8, 4}}, []string{
+			"    *    ",
+			"   * *   ",
+			"  *   *  ",
+			" *     * ",
+			"*********",
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := DrawPoints(VectorToRaster(&VectorImage{Lines: tc.lines}))
+			want := strings.Join(tc.golden, "\n") + "\n"
+			if got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+)
+
+type ImageOptions struct {
+	Foreground color.Color //	defaults to black
+	Background color.Color //	defaults to white
+	Scale      int         //	every point becomes a Scale x Scale square, defaults to 1
+}
+
+// second adapter, exposes any RasterImage as an image.Image from the standard library
+type rasterToImageAdapter struct {
+	points map[Point]bool
+	bounds image.Rectangle
+	scale  int
+	model  color.Palette
+}
+
+func (a *rasterToImageAdapter) ColorModel() color.Model {
+	return a.model
+}
+
+func (a *rasterToImageAdapter) Bounds() image.Rectangle {
+	return a.bounds
+}
+
+func (a *rasterToImageAdapter) At(x, y int) color.Color {
+	if !(image.Point{x, y}).In(a.bounds) {
+		return a.model[0]
+	}
+	if a.points[Point{floorDiv(x, a.scale), floorDiv(y, a.scale)}] {
+		return a.model[1]
+	}
+	return a.model[0]
+}
+
+func floorDiv(a, b int) int {
+	if a < 0 {
+		return -((-a + b - 1) / b)
+	}
+	return a / b
+}
+
+// the image is sized the same way as DrawPoints sizes its canvas, negative points move the top left corner of the bounds
+func RasterToImage(ri RasterImage, opts ImageOptions) image.Image {
+	if opts.Foreground == nil {
+		opts.Foreground = color.Black
+	}
+	if opts.Background == nil {
+		opts.Background = color.White
+	}
+	if opts.Scale < 1 {
+		opts.Scale = 1
+	}
+
+	adapter := rasterToImageAdapter{
+		points: map[Point]bool{},
+		scale:  opts.Scale,
+		model:  color.Palette{opts.Background, opts.Foreground},
+	}
+
+	points := ri.GetPoints()
+	for _, p := range points {
+		adapter.points[p] = true
+	}
+
+	vp := BoundingBox(points).Include(Point{0, 0})
+	s := opts.Scale
+	adapter.bounds = image.Rect(vp.X*s, vp.Y*s, (vp.X+vp.Width)*s, (vp.Y+vp.Height)*s)
+
+	return &adapter
+}
+
+func WritePNG(w io.Writer, img image.Image) error {
+	return png.Encode(w, img)
+}
+
+// writes the image as a plain (P1) netpbm bitmap, dark pixels become 1
+func WritePBM(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "P1\n%d %d\n", b.Dx(), b.Dy())
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			//	lines of a pbm file should not be longer than 70 characters
+			if x > b.Min.X {
+				if (x-b.Min.X)%35 == 0 {
+					bw.WriteByte('\n')
+				} else {
+					bw.WriteByte(' ')
+				}
+			}
+
+			gray := color.GrayModel.Convert(img.At(x, y)).(color.Gray)
+			if gray.Y < 128 {
+				bw.WriteByte('1')
+			} else {
+				bw.WriteByte('0')
+			}
+		}
+		bw.WriteByte('\n')
+	}
+
+	return bw.Flush()
+}
+
+func TestRasterToImage() {
+	ri := VectorToRaster(&VectorImage{Lines: []Line{{0, 0, 4, 4}, {0, 4, 4, 0}}})
+
+	img := RasterToImage(ri, ImageOptions{})
+	if err := WritePBM(os.Stdout, img); err != nil {
+		fmt.Println("error writing pbm:", err)
+	}
+
+	//	the png should decode back into the same pixels
+	scaled := RasterToImage(ri, ImageOptions{
+		Foreground: color.RGBA{R: 200, A: 255},
+		Scale:      4,
+	})
+	buf := bytes.Buffer{}
+	if err := WritePNG(&buf, scaled); err != nil {
+		fmt.Println("error writing png:", err)
+		return
+	}
+	decoded, err := png.Decode(&buf)
+	if err != nil {
+		fmt.Println("error reading png:", err)
+		return
+	}
+
+	same := decoded.Bounds() == scaled.Bounds()
+	for y := 0; same && y < decoded.Bounds().Dy(); y++ {
+		for x := 0; x < decoded.Bounds().Dx(); x++ {
+			r1, g1, b1, a1 := decoded.At(x, y).RGBA()
+			r2, g2, b2, a2 := scaled.At(x, y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				same = false
+				break
+			}
+		}
+	}
+	fmt.Println("png is", decoded.Bounds().Dx(), "x", decoded.Bounds().Dy(), "and round trips:", same)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"container/list"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// caching the points generated for every line so we dont have to do it again and again
+// the cache is bounded, evicts the least recently used line and is safe to share between goroutines
+type PointCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[[16]byte]*list.Element
+	order    *list.List //	front is the most recently used
+	hits     int
+	misses   int
+}
+
+type pointCacheEntry struct {
+	key    [16]byte
+	points []Point
+}
+
+func NewPointCache(capacity int) *PointCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &PointCache{
+		capacity: capacity,
+		entries:  map[[16]byte]*list.Element{},
+		order:    new(list.List),
+	}
+}
+
+func hashLine(line Line) [16]byte {
+	bytes, _ := json.Marshal(line)
+	return md5.Sum(bytes)
+}
+
+// returns the points of a single line, the slice is a copy so callers can append to it freely
+func (c *PointCache) Get(line Line) ([]Point, bool) {
+	h := hashLine(line)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[h]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+
+	c.hits++
+	c.order.MoveToFront(el)
+	pts := el.Value.(*pointCacheEntry).points
+	return append([]Point(nil), pts...), true
+}
+
+func (c *PointCache) Put(line Line, points []Point) {
+	h := hashLine(line)
+	points = append([]Point(nil), points...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[h]; ok {
+		el.Value.(*pointCacheEntry).points = points
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[h] = c.order.PushFront(&pointCacheEntry{key: h, points: points})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*pointCacheEntry).key)
+	}
+}
+
+func (c *PointCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *PointCache) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
+
+func TestPointCache() {
+	cache := NewPointCache(4)
+
+	//	the rectangle has 4 lines, so the second call is served entirely from the cache
+	VectorToRasterCached(NewRectangle(30, 10), cache)
+	VectorToRasterCached(NewRectangle(30, 10), cache)
+	hits, misses := cache.Stats()
+	fmt.Println("hits:", hits, "misses:", misses, "size:", cache.Len())
+
+	//	a different rectangle pushes all the old lines out
+	VectorToRasterCached(NewRectangle(20, 5), cache)
+	hits, misses = cache.Stats()
+	fmt.Println("hits:", hits, "misses:", misses, "size:", cache.Len())
+
+	//	many goroutines sharing one cache
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(size int) {
+			defer wg.Done()
+			VectorToRasterCached(NewRectangle(size, size), cache)
+		}(3 + i%2)
+	}
+	wg.Wait()
+	hits, misses = cache.Stats()
+	fmt.Println("hits:", hits, "misses:", misses, "size:", cache.Len())
+}
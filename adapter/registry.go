@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"reflect"
+)
+
+var ErrNoConversion = errors.New("no conversion path")
+
+type conversion struct {
+	from, to reflect.Type
+	convert  func(any) (any, error)
+}
+
+// registry of adapters, conversions are registered once and chained together when asked for
+type AdapterRegistry struct {
+	conversions []conversion
+}
+
+func NewAdapterRegistry() *AdapterRegistry {
+	return &AdapterRegistry{}
+}
+
+// methods cant have type parameters in go, so registering is a function
+func Register[From, To any](r *AdapterRegistry, adapter func(From) To) {
+	r.conversions = append(r.conversions, conversion{
+		from: reflect.TypeFor[From](),
+		to:   reflect.TypeFor[To](),
+		//	an adapter returning a nil interface leaves nothing for the next one to take
+		convert: func(v any) (any, error) {
+			from, ok := v.(From)
+			if !ok {
+				return nil, fmt.Errorf("conversion to %v got %T, not %v", reflect.TypeFor[To](), v, reflect.TypeFor[From]())
+			}
+			return adapter(from), nil
+		},
+	})
+}
+
+// a value of type t can go into a conversion taking `from` if it is the same type or implements the interface
+func accepts(from, t reflect.Type) bool {
+	return t == from || (from.Kind() == reflect.Interface && t.Implements(from))
+}
+
+// breadth first search, so the chain found is the shortest one
+func (r *AdapterRegistry) path(from, to reflect.Type) ([]conversion, error) {
+	if accepts(to, from) {
+		return nil, nil
+	}
+
+	type step struct {
+		t    reflect.Type
+		path []conversion
+	}
+	seen := map[reflect.Type]bool{from: true}
+	queue := []step{{t: from}}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		for _, c := range r.conversions {
+			if seen[c.to] || !accepts(c.from, cur.t) {
+				continue
+			}
+			seen[c.to] = true
+
+			path := append(append([]conversion(nil), cur.path...), c)
+			if accepts(to, c.to) {
+				return path, nil
+			}
+			queue = append(queue, step{t: c.to, path: path})
+		}
+	}
+
+	return nil, fmt.Errorf("%w from %v to %v", ErrNoConversion, from, to)
+}
+
+// the types that a value goes through on its way from one type to the other
+func (r *AdapterRegistry) Path(from, to reflect.Type) ([]reflect.Type, error) {
+	path, err := r.path(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	types := []reflect.Type{from}
+	for _, c := range path {
+		types = append(types, c.to)
+	}
+	return types, nil
+}
+
+func Convert[To any](r *AdapterRegistry, value any) (To, error) {
+	var zero To
+	if value == nil {
+		return zero, fmt.Errorf("%w from nil", ErrNoConversion)
+	}
+
+	path, err := r.path(reflect.TypeOf(value), reflect.TypeFor[To]())
+	if err != nil {
+		return zero, err
+	}
+
+	for _, c := range path {
+		if value, err = c.convert(value); err != nil {
+			return zero, err
+		}
+	}
+	result, ok := value.(To)
+	if !ok {
+		return zero, fmt.Errorf("conversion to %v got %T", reflect.TypeFor[To](), value)
+	}
+	return result, nil
+}
+
+func TestAdapterRegistry() {
+	r := NewAdapterRegistry()
+	Register(r, VectorToRaster)
+	Register(r, RasterToVector)
+	Register(r, func(ri RasterImage) image.Image {
+		return RasterToImage(ri, ImageOptions{})
+	})
+
+	//	*VectorImage -> RasterImage -> image.Image
+	path, _ := r.Path(reflect.TypeFor[*VectorImage](), reflect.TypeFor[image.Image]())
+	fmt.Println("path:", path)
+
+	img, err := Convert[image.Image](r, NewRectangle(30, 10))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("converted into an image of size", img.Bounds().Size())
+
+	_, err = Convert[string](r, NewRectangle(30, 10))
+	fmt.Println(err, "| is ErrNoConversion:", errors.Is(err, ErrNoConversion))
+
+	//	an adapter giving back nothing is an error and not a panic in the next step
+	broken := NewAdapterRegistry()
+	Register(broken, func(vi *VectorImage) RasterImage { return nil })
+	Register(broken, func(ri RasterImage) image.Image { return RasterToImage(ri, ImageOptions{}) })
+	_, err = Convert[image.Image](broken, NewRectangle(30, 10))
+	fmt.Println(err)
+	_, err = Convert[RasterImage](broken, NewRectangle(30, 10))
+	fmt.Println(err)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// curves are approximated with straight lines so the rest of the adapter only ever deals with lines
+// 0 degrees points to the right and angles grow clockwise, as y grows downwards on the canvas
+
+func NewCircle(cx, cy, radius int) *VectorImage {
+	return NewEllipse(cx, cy, radius, radius)
+}
+
+func NewEllipse(cx, cy, rx, ry int) *VectorImage {
+	return &VectorImage{Lines: arcLines(cx, cy, rx, ry, 0, 360)}
+}
+
+// an arc of a circle from the start angle to the end angle, in degrees.
+// an end before the start wraps around through 0, an angle that is NaN or infinite draws nothing
+func NewArc(cx, cy, radius int, startDeg, endDeg float64) *VectorImage {
+	return &VectorImage{Lines: arcLines(cx, cy, radius, radius, startDeg, endDeg)}
+}
+
+// regular polygon with its corners on a circle, the first corner is at rotationDeg
+func NewRegularPolygon(cx, cy, radius, sides int, rotationDeg float64) *VectorImage {
+	if sides < 3 {
+		return &VectorImage{}
+	}
+
+	corners := make([]Point, 0, sides+1)
+	for i := 0; i <= sides; i++ {
+		corners = append(corners, pointOnEllipse(cx, cy, radius, radius, rotationDeg+float64(i)*360/float64(sides)))
+	}
+
+	return &VectorImage{Lines: connectPoints(corners)}
+}
+
+func pointOnEllipse(cx, cy, rx, ry int, deg float64) Point {
+	rad := deg * math.Pi / 180
+	return Point{
+		cx + int(math.Round(float64(rx)*math.Cos(rad))),
+		cy + int(math.Round(float64(ry)*math.Sin(rad))),
+	}
+}
+
+func arcLines(cx, cy, rx, ry int, startDeg, endDeg float64) []Line {
+	if math.IsNaN(startDeg) || math.IsInf(startDeg, 0) || math.IsNaN(endDeg) || math.IsInf(endDeg, 0) {
+		return nil
+	}
+
+	//	the arc always runs with increasing angles, so 350 to 10 wraps through 0
+	sweep := 360.0
+	if endDeg-startDeg < 360 {
+		sweep = math.Mod(math.Mod(endDeg, 360)-math.Mod(startDeg, 360), 360)
+		if sweep < 0 {
+			sweep += 360
+		}
+	}
+	startDeg = math.Mod(startDeg, 360)
+
+	//	roughly one segment for every pixel of the outline, but never fewer than 8 for a full turn
+	perimeter := math.Pi * float64(rx+ry) * sweep / 360
+	segments := max(int(math.Ceil(perimeter)), int(math.Ceil(8*sweep/360)), 1)
+	if sweep == 360 {
+		//	a multiple of 4 keeps the corners mirrored in every quadrant
+		segments = (segments + 3) / 4 * 4
+	}
+
+	pts := make([]Point, 0, segments+1)
+	for i := 0; i <= segments; i++ {
+		pts = append(pts, pointOnEllipse(cx, cy, rx, ry, startDeg+sweep*float64(i)/float64(segments)))
+	}
+
+	return connectPoints(pts)
+}
+
+// joins the points in order, dropping the zero length lines that rounding creates
+func connectPoints(pts []Point) []Line {
+	var lines []Line
+	for i := 1; i < len(pts); i++ {
+		if pts[i] == pts[i-1] {
+			continue
+		}
+		lines = append(lines, Line{pts[i-1].X, pts[i-1].Y, pts[i].X, pts[i].Y})
+	}
+
+	if len(lines) == 0 && len(pts) > 0 {
+		lines = append(lines, Line{pts[0].X, pts[0].Y, pts[0].X, pts[0].Y})
+	}
+	return lines
+}
+
+func TestShapes() {
+	fmt.Println(DrawPoints(VectorToRaster(NewCircle(8, 8, 8))))
+	fmt.Println(DrawPoints(VectorToRaster(NewEllipse(15, 5, 15, 5))))
+	fmt.Println(DrawPoints(VectorToRaster(NewRegularPolygon(8, 8, 8, 6, 0))))
+	fmt.Println(DrawPoints(VectorToRaster(NewArc(8, 8, 8, 180, 360))))
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+)
+
+// streaming variant of RasterImage, the points are produced only when they are ranged over
+// GetPoints is still there so it can be used anywhere a RasterImage is expected
+type StreamingRasterImage interface {
+	RasterImage
+	Points() iter.Seq[Point]
+}
+
+// images which can skip the work for everything outside of a viewport
+type clippedRasterImage interface {
+	PointsIn(viewport Viewport) iter.Seq[Point]
+}
+
+// the points of any kind of RasterImage, without collecting a streaming one into a slice
+func pointsOf(owner RasterImage) iter.Seq[Point] {
+	if s, ok := owner.(StreamingRasterImage); ok {
+		return s.Points()
+	}
+	return slices.Values(owner.GetPoints())
+}
+
+// lazy adapter, keeps the lines and rasterizes them every time the points are asked for
+type vectorToRasterStream struct {
+	lines []Line
+	cache *PointCache
+}
+
+func (s *vectorToRasterStream) GetPoints() []Point {
+	return slices.Collect(s.Points())
+}
+
+func (s *vectorToRasterStream) Points() iter.Seq[Point] {
+	return s.PointsIn(Viewport{})
+}
+
+// an empty viewport means everything
+func (s *vectorToRasterStream) PointsIn(viewport Viewport) iter.Seq[Point] {
+	return func(yield func(Point) bool) {
+		for _, line := range s.lines {
+			if !viewport.Empty() && !viewport.Overlaps(BoundingBox([]Point{{line.X1, line.Y1}, {line.X2, line.Y2}})) {
+				continue
+			}
+
+			for p := range s.linePoints(line) {
+				if !yield(p) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *vectorToRasterStream) linePoints(line Line) iter.Seq[Point] {
+	if s.cache == nil {
+		return rasterizeLineSeq(line)
+	}
+
+	pts, ok := s.cache.Get(line)
+	if !ok {
+		pts = rasterizeLine(line)
+		s.cache.Put(line, pts)
+	}
+	return slices.Values(pts)
+}
+
+// cache can be nil, the lines of vi are copied so changing it later does not change the stream
+func VectorToRasterStream(vi *VectorImage, cache *PointCache) StreamingRasterImage {
+	return &vectorToRasterStream{
+		lines: slices.Clone(vi.Lines),
+		cache: cache,
+	}
+}
+
+func TestStreaming() {
+	//	a long row of boxes of which only the first few are ever looked at
+	vi := &VectorImage{}
+	for i := 0; i < 1000; i++ {
+		for _, l := range NewRectangle(5, 5).Lines {
+			vi.Lines = append(vi.Lines, Line{l.X1 + i*6, l.Y1, l.X2 + i*6, l.Y2})
+		}
+	}
+
+	cache := NewPointCache(256)
+	stream := VectorToRasterStream(vi, cache)
+	fmt.Println(DrawPointsIn(stream, Viewport{X: 0, Y: 0, Width: 20, Height: 5}))
+	_, misses := cache.Stats()
+	fmt.Println("lines rasterized for the window:", misses, "out of", len(vi.Lines))
+
+	//	stopping early never rasterizes the rest
+	count := 0
+	for p := range stream.Points() {
+		if p.X > 10 {
+			break
+		}
+		count++
+	}
+	fmt.Println("points before x > 10:", count)
+
+	//	same drawing either way
+	small := NewRectangle(8, 4)
+	fmt.Println(DrawPoints(VectorToRasterStream(small, nil)) == DrawPoints(VectorToRaster(small)))
+
+	//	DrawPoints goes over the stream once, so every line is looked up once
+	counted := NewPointCache(16)
+	DrawPoints(VectorToRasterStream(small, counted))
+	hits, misses := counted.Stats()
+	fmt.Println("lookups for", len(small.Lines), "lines:", hits+misses)
+}
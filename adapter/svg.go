@@ -0,0 +1,375 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnsupportedElement     = errors.New("unsupported element")
+	ErrUnsupportedPathCommand = errors.New("unsupported path command")
+	ErrInvalidAttribute       = errors.New("invalid attribute")
+)
+
+// describes a single svg element that could not be converted into lines
+type SVGError struct {
+	Element string
+	Offset  int64 //	byte offset of the element in the input
+	Err     error
+	Detail  string
+}
+
+func (e *SVGError) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("svg <%s> at offset %d: %v", e.Element, e.Offset, e.Err)
+	}
+	return fmt.Sprintf("svg <%s> at offset %d: %v: %s", e.Element, e.Offset, e.Err, e.Detail)
+}
+
+func (e *SVGError) Unwrap() error {
+	return e.Err
+}
+
+// elements which dont draw anything on their own
+var svgContainers = map[string]bool{
+	"svg":   true,
+	"g":     true,
+	"title": true,
+	"desc":  true,
+}
+
+// loads the svg and converts every supported element into lines
+// an image with everything that could be converted is returned along with the joined errors of the rest
+func LoadSVG(r io.Reader) (*VectorImage, error) {
+	vi := &VectorImage{}
+	var errs []error
+
+	d := xml.NewDecoder(r)
+	for {
+		offset := d.InputOffset()
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return vi, errors.Join(append(errs, err)...)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok || svgContainers[start.Name.Local] {
+			continue
+		}
+
+		lines, err := svgElementLines(start)
+		if err != nil {
+			errs = append(errs, newSVGError(start.Name.Local, offset, err))
+
+			//	children of an unsupported element are never looked at
+			if err := d.Skip(); err != nil {
+				return vi, errors.Join(append(errs, err)...)
+			}
+			continue
+		}
+		vi.Lines = append(vi.Lines, lines...)
+	}
+
+	return vi, errors.Join(errs...)
+}
+
+func LoadSVGFile(path string) (*VectorImage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadSVG(f)
+}
+
+// the sentinel is kept in Err, whatever was wrapped around it goes into the detail
+type svgDetailError struct {
+	err    error
+	detail string
+}
+
+func (e *svgDetailError) Error() string {
+	return fmt.Sprintf("%v: %s", e.err, e.detail)
+}
+
+func newSVGError(element string, offset int64, err error) *SVGError {
+	e := &SVGError{Element: element, Offset: offset, Err: err}
+
+	var de *svgDetailError
+	if errors.As(err, &de) {
+		e.Err, e.Detail = de.err, de.detail
+	}
+	return e
+}
+
+func svgDetail(err error, format string, args ...any) error {
+	return &svgDetailError{err: err, detail: fmt.Sprintf(format, args...)}
+}
+
+func svgElementLines(el xml.StartElement) ([]Line, error) {
+	attr := func(name string) string {
+		for _, a := range el.Attr {
+			if a.Name.Local == name {
+				return a.Value
+			}
+		}
+		return ""
+	}
+
+	switch el.Name.Local {
+	case "line":
+		v, err := svgNumbers(attr, "x1", "y1", "x2", "y2")
+		if err != nil {
+			return nil, err
+		}
+		return []Line{svgLine(v[0], v[1], v[2], v[3])}, nil
+
+	case "rect":
+		v, err := svgNumbers(attr, "x", "y", "width", "height")
+		if err != nil {
+			return nil, err
+		}
+		x1, y1, x2, y2 := v[0], v[1], v[0]+v[2], v[1]+v[3]
+		return []Line{
+			svgLine(x1, y1, x2, y1),
+			svgLine(x1, y1, x1, y2),
+			svgLine(x2, y1, x2, y2),
+			svgLine(x1, y2, x2, y2),
+		}, nil
+
+	case "polyline", "polygon":
+		pts, err := svgPoints(attr("points"))
+		if err != nil {
+			return nil, err
+		}
+		return svgPolyline(pts, el.Name.Local == "polygon"), nil
+
+	case "path":
+		return svgPath(attr("d"))
+	}
+
+	return nil, ErrUnsupportedElement
+}
+
+func svgLine(x1, y1, x2, y2 float64) Line {
+	return Line{
+		int(math.Round(x1)), int(math.Round(y1)),
+		int(math.Round(x2)), int(math.Round(y2)),
+	}
+}
+
+func svgPolyline(pts [][2]float64, closed bool) []Line {
+	var lines []Line
+	for i := 1; i < len(pts); i++ {
+		lines = append(lines, svgLine(pts[i-1][0], pts[i-1][1], pts[i][0], pts[i][1]))
+	}
+	if closed && len(pts) > 2 {
+		last := pts[len(pts)-1]
+		lines = append(lines, svgLine(last[0], last[1], pts[0][0], pts[0][1]))
+	}
+	return lines
+}
+
+// missing attributes default to 0 like they do in svg
+func svgNumbers(attr func(string) string, names ...string) ([]float64, error) {
+	values := make([]float64, len(names))
+	for i, name := range names {
+		raw := strings.TrimSpace(attr(name))
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.TrimSuffix(raw, "px"), 64)
+		if err != nil {
+			return nil, svgDetail(ErrInvalidAttribute, "%s=%q", name, raw)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func svgPoints(raw string) ([][2]float64, error) {
+	tokens := svgTokens(raw)
+	if len(tokens)%2 != 0 {
+		return nil, svgDetail(ErrInvalidAttribute, "points=%q has an odd number of coordinates", raw)
+	}
+
+	var pts [][2]float64
+	for i := 0; i < len(tokens); i += 2 {
+		x, errX := strconv.ParseFloat(tokens[i], 64)
+		y, errY := strconv.ParseFloat(tokens[i+1], 64)
+		if errX != nil || errY != nil {
+			return nil, svgDetail(ErrInvalidAttribute, "points=%q", raw)
+		}
+		pts = append(pts, [2]float64{x, y})
+	}
+	return pts, nil
+}
+
+// splits path data into command letters and numbers, "M10-5L3,4" becomes [M 10 -5 L 3 4]
+func svgTokens(raw string) []string {
+	var tokens []string
+	cur := strings.Builder{}
+	flush := func() {
+		if cur.Len() > 0 {
+			tokens = append(tokens, cur.String())
+			cur.Reset()
+		}
+	}
+
+	for i, c := range raw {
+		switch {
+		case c == ',' || c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			flush()
+		case c == '-' || c == '+':
+			//	a sign starts a new number unless it belongs to an exponent
+			if i > 0 && (raw[i-1] == 'e' || raw[i-1] == 'E') {
+				cur.WriteRune(c)
+				continue
+			}
+			flush()
+			cur.WriteRune(c)
+		case c == '.' && strings.Contains(cur.String(), "."):
+			//	"0.5.5" is two numbers
+			flush()
+			cur.WriteRune(c)
+		case (c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') && c != 'e' && c != 'E':
+			flush()
+			tokens = append(tokens, string(c))
+		default:
+			cur.WriteRune(c)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// supports absolute and relative M, L, H, V and Z
+func svgPath(d string) ([]Line, error) {
+	tokens := svgTokens(d)
+
+	var lines []Line
+	var x, y, startX, startY float64
+	cmd := ""
+	i := 0
+
+	next := func() (float64, error) {
+		if i >= len(tokens) {
+			return 0, svgDetail(ErrInvalidAttribute, "d=%q ends in the middle of %s", d, cmd)
+		}
+		v, err := strconv.ParseFloat(tokens[i], 64)
+		if err != nil {
+			return 0, svgDetail(ErrInvalidAttribute, "d=%q has %q where a number was expected", d, tokens[i])
+		}
+		i++
+		return v, nil
+	}
+
+	for i < len(tokens) {
+		if _, err := strconv.ParseFloat(tokens[i], 64); err != nil {
+			cmd = tokens[i]
+			i++
+		} else if cmd == "" {
+			return nil, svgDetail(ErrInvalidAttribute, "d=%q does not start with a command", d)
+		}
+
+		relative := strings.ToLower(cmd) == cmd
+		switch strings.ToUpper(cmd) {
+		case "M", "L":
+			nx, err := next()
+			if err != nil {
+				return nil, err
+			}
+			ny, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if relative {
+				nx, ny = x+nx, y+ny
+			}
+
+			if strings.ToUpper(cmd) == "M" {
+				startX, startY = nx, ny
+				//	extra coordinate pairs after a move are treated as lines
+				if relative {
+					cmd = "l"
+				} else {
+					cmd = "L"
+				}
+			} else {
+				lines = append(lines, svgLine(x, y, nx, ny))
+			}
+			x, y = nx, ny
+
+		case "H":
+			nx, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if relative {
+				nx += x
+			}
+			lines = append(lines, svgLine(x, y, nx, y))
+			x = nx
+
+		case "V":
+			ny, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if relative {
+				ny += y
+			}
+			lines = append(lines, svgLine(x, y, x, ny))
+			y = ny
+
+		case "Z":
+			if x != startX || y != startY {
+				lines = append(lines, svgLine(x, y, startX, startY))
+			}
+			x, y = startX, startY
+			cmd = ""
+
+		default:
+			return nil, svgDetail(ErrUnsupportedPathCommand, "%q in d=%q", cmd, d)
+		}
+	}
+
+	return lines, nil
+}
+
+func TestSVG() {
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" width="40" height="12">
+  <title>assets</title>
+  <rect x="0" y="0" width="10" height="5"/>
+  <line x1="12" y1="0" x2="18" y2="5"/>
+  <g>
+    <polygon points="20,5 24,0 28,5"/>
+    <polyline points="30 0, 32 5 34 0"/>
+  </g>
+  <path d="M0,7 h10 v4 H0 z m14 0 l4 4 l4 -4"/>
+  <circle cx="5" cy="5" r="4"/>
+  <path d="M0 0 C 1 1 2 2 3 3"/>
+</svg>`
+
+	vi, err := LoadSVG(strings.NewReader(svg))
+	fmt.Println("loaded", len(vi.Lines), "lines")
+	if err != nil {
+		fmt.Println(err)
+	}
+	if errors.Is(err, ErrUnsupportedElement) {
+		fmt.Println("the svg has elements that cant be converted")
+	}
+
+	fmt.Println(DrawPoints(VectorToRaster(vi)))
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// reverse adapter, traces the points of any RasterImage back into lines
+// horizontal, vertical and 45 degree runs are found first, a line of any other slope rasterizes
+// into a chain of parallel runs a fixed step apart, and those chains are joined back into one line
+type rasterToVectorAdapter struct {
+	points map[Point]bool
+}
+
+// the directions that a run can go in, only forwards so every run is found once
+var traceDirections = []Point{{1, 0}, {0, 1}, {1, 1}, {-1, 1}}
+
+// finds the longest run through every point in every direction
+func (a *rasterToVectorAdapter) runs() []Line {
+	var runs []Line
+	for p := range a.points {
+		for _, d := range traceDirections {
+			//	only start from the first point of a run
+			if a.points[Point{p.X - d.X, p.Y - d.Y}] {
+				continue
+			}
+
+			end := p
+			for a.points[Point{end.X + d.X, end.Y + d.Y}] {
+				end = Point{end.X + d.X, end.Y + d.Y}
+			}
+			if end != p {
+				runs = append(runs, Line{p.X, p.Y, end.X, end.Y})
+			}
+		}
+	}
+
+	return runs
+}
+
+func sign(a int) int {
+	switch {
+	case a < 0:
+		return -1
+	case a > 0:
+		return 1
+	}
+	return 0
+}
+
+func runDirection(l Line) Point {
+	return Point{sign(l.X2 - l.X1), sign(l.Y2 - l.Y1)}
+}
+
+// the steps between the end of one run and the start of the next
+var chainSteps = []Point{{1, 0}, {0, 1}, {1, 1}, {-1, 1}, {-1, 0}, {0, -1}, {-1, -1}, {1, -1}}
+
+// chains of parallel runs where every run starts one step after the previous one ends,
+// which is how bresenham draws a line that is not straight or diagonal.
+// a run can carry on into a shape the line crosses, so the next run is the part after that step
+func (a *rasterToVectorAdapter) chainsOf(runs []Line) [][]Line {
+	//	the run through every point, for each direction
+	on := map[Point]map[Point]Line{}
+	ends := map[Point]map[Point]bool{}
+	for _, r := range runs {
+		d := runDirection(r)
+		if on[d] == nil {
+			on[d], ends[d] = map[Point]Line{}, map[Point]bool{}
+		}
+		for _, p := range rasterizeLine(r) {
+			on[d][p] = r
+		}
+		ends[d][Point{r.X2, r.Y2}] = true
+	}
+
+	var chains [][]Line
+	for _, step := range chainSteps {
+		for _, r := range runs {
+			//	bresenham only ever steps in the direction of the runs or the one next to it
+			d := runDirection(r)
+			side := Point{step.X - d.X, step.Y - d.Y}
+			if abs(side.X)+abs(side.Y) != 1 {
+				continue
+			}
+			//	only start from the first run of a chain
+			if ends[d][Point{r.X1 - step.X, r.Y1 - step.Y}] {
+				continue
+			}
+
+			chain := []Line{r}
+			for len(chain) <= len(runs) {
+				last := chain[len(chain)-1]
+				//	a set pixel beside the end means a filled area and not a line one pixel wide,
+				//	except where the line starts, which can be a corner shared with another line
+				if len(chain) > 1 && a.points[Point{last.X2 + side.X, last.Y2 + side.Y}] {
+					break
+				}
+				p := Point{last.X2 + step.X, last.Y2 + step.Y}
+				next, ok := on[d][p]
+				if !ok {
+					break
+				}
+				chain = append(chain, Line{p.X, p.Y, next.X2, next.Y2})
+			}
+			if len(chain) > 1 && regularRuns(chain) {
+				chains = append(chains, chain)
+			}
+		}
+	}
+
+	return chains
+}
+
+// the runs of a bresenham line differ in length by one at most, only the first and last can be shorter
+func regularRuns(chain []Line) bool {
+	first, last := lineLength(chain[0]), lineLength(chain[len(chain)-1])
+	if len(chain) == 2 {
+		return abs(first-last) <= 1
+	}
+
+	shortest, longest := lineLength(chain[1]), lineLength(chain[1])
+	for _, r := range chain[1 : len(chain)-1] {
+		shortest, longest = min(shortest, lineLength(r)), max(longest, lineLength(r))
+	}
+	return longest-shortest <= 1 && first <= longest+1 && last <= longest+1
+}
+
+// lines which join a chain of runs back together, a join has to pass through every run in between
+// and stay on set pixels, the runs at either end can reach past the line into a shape it touches
+func (a *rasterToVectorAdapter) joins(runs []Line) []Line {
+	var joins []Line
+	for _, chain := range a.chainsOf(runs) {
+		for i := 0; i < len(chain)-1; {
+			//	the rounding of a part of a line can differ from the whole, so the longest join that fits wins
+			j := len(chain) - 1
+			for ; j > i; j-- {
+				l := Line{chain[i].X1, chain[i].Y1, chain[j].X2, chain[j].Y2}
+				if a.follows(l, chain[i+1:j]) {
+					joins = append(joins, l)
+					break
+				}
+			}
+			i = max(j, i+1)
+		}
+	}
+	return joins
+}
+
+// every pixel of the line is set and every pixel of the runs is on the line
+func (a *rasterToVectorAdapter) follows(l Line, through []Line) bool {
+	on := map[Point]bool{}
+	for p := range rasterizeLineSeq(l) {
+		if !a.points[p] {
+			return false
+		}
+		on[p] = true
+	}
+	for _, r := range through {
+		for _, p := range rasterizeLine(r) {
+			if !on[p] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func lineLength(l Line) int {
+	return max(abs(l.X2-l.X1), abs(l.Y2-l.Y1))
+}
+
+func (a *rasterToVectorAdapter) trace() *VectorImage {
+	runs := a.runs()
+	runs = append(runs, a.joins(runs)...)
+
+	//	longest first so that they swallow the shorter runs crossing them
+	sort.Slice(runs, func(i, j int) bool {
+		li, lj := lineLength(runs[i]), lineLength(runs[j])
+		if li != lj {
+			return li > lj
+		}
+		if runs[i].Y1 != runs[j].Y1 {
+			return runs[i].Y1 < runs[j].Y1
+		}
+		if runs[i].X1 != runs[j].X1 {
+			return runs[i].X1 < runs[j].X1
+		}
+		if runs[i].Y2 != runs[j].Y2 {
+			return runs[i].Y2 < runs[j].Y2
+		}
+		return runs[i].X2 < runs[j].X2
+	})
+
+	covered := map[Point]int{}
+	var picked []Line
+	for _, run := range runs {
+		pts := rasterizeLine(run)
+		useful := false
+		for _, p := range pts {
+			if covered[p] == 0 {
+				useful = true
+				break
+			}
+		}
+		if !useful {
+			continue
+		}
+
+		picked = append(picked, run)
+		for _, p := range pts {
+			covered[p]++
+		}
+	}
+
+	//	a short run picked early can end up fully covered by the ones after it
+	redundant := make([]bool, len(picked))
+	for i := len(picked) - 1; i >= 0; i-- {
+		pts := rasterizeLine(picked[i])
+		redundant[i] = true
+		for _, p := range pts {
+			if covered[p] < 2 {
+				redundant[i] = false
+				break
+			}
+		}
+		if redundant[i] {
+			for _, p := range pts {
+				covered[p]--
+			}
+		}
+	}
+
+	vi := &VectorImage{}
+	for i, l := range picked {
+		if !redundant[i] {
+			vi.Lines = append(vi.Lines, l)
+		}
+	}
+
+	//	lone points are kept as zero length lines
+	lone := []Point{}
+	for p := range a.points {
+		if covered[p] == 0 {
+			lone = append(lone, p)
+		}
+	}
+	sort.Slice(lone, func(i, j int) bool {
+		if lone[i].Y != lone[j].Y {
+			return lone[i].Y < lone[j].Y
+		}
+		return lone[i].X < lone[j].X
+	})
+	for _, p := range lone {
+		vi.Lines = append(vi.Lines, Line{p.X, p.Y, p.X, p.Y})
+	}
+
+	return vi
+}
+
+// lines that cross in the middle can come back split at the crossing, and filled areas come back
+// as rows or columns, but redrawing the result always gives exactly the same points
+func RasterToVector(ri RasterImage) *VectorImage {
+	adapter := rasterToVectorAdapter{points: map[Point]bool{}}
+	for _, p := range ri.GetPoints() {
+		adapter.points[p] = true
+	}
+
+	return adapter.trace()
+}
+
+// lines are equal no matter which end they start from
+func sameLines(a, b []Line) bool {
+	normalize := func(l Line) Line {
+		if l.X1 > l.X2 || (l.X1 == l.X2 && l.Y1 > l.Y2) {
+			return Line{l.X2, l.Y2, l.X1, l.Y1}
+		}
+		return l
+	}
+
+	count := map[Line]int{}
+	for _, l := range a {
+		count[normalize(l)]++
+	}
+	for _, l := range b {
+		count[normalize(l)]--
+	}
+	for _, c := range count {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRasterToVector() {
+	rc := NewRectangle(30, 10)
+	traced := RasterToVector(VectorToRaster(rc))
+	fmt.Println("rectangle traced into", traced.Lines)
+	fmt.Println("round trip gives the same lines:", sameLines(rc.Lines, traced.Lines))
+
+	triangle := &VectorImage{Lines: []Line{{0, 4, 4, 0}, {4, 0, 8, 4}, {0, 4, 8, 4}, {10, 2, 10, 2}}}
+	traced = RasterToVector(VectorToRaster(triangle))
+	fmt.Println("triangle traced into", traced.Lines)
+	fmt.Println("round trip gives the same lines:", sameLines(triangle.Lines, traced.Lines))
+
+	//	slopes which rasterize into runs of several pixels still come back as one line each
+	sloped := &VectorImage{Lines: []Line{{0, 0, 12, 3}, {0, 0, 2, 9}, {12, 3, 2, 9}}}
+	traced = RasterToVector(VectorToRaster(sloped))
+	fmt.Println("sloped triangle traced into", traced.Lines)
+	fmt.Println("round trip gives the same lines:", sameLines(sloped.Lines, traced.Lines))
+
+	//	a filled area comes back as one line per row, not as lines cutting across it
+	block := &VectorImage{}
+	for y := 0; y < 8; y++ {
+		block.Lines = append(block.Lines, Line{0, y, 19, y})
+	}
+	traced = RasterToVector(VectorToRaster(block))
+	fmt.Println("filled block traced into", len(traced.Lines), "lines")
+	fmt.Println("round trip gives the same lines:", sameLines(block.Lines, traced.Lines))
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+)
+
+// a window onto the plane, X and Y is the point drawn in the top left corner
+// anything outside of the window gets clipped
+type Viewport struct {
+	X, Y          int
+	Width, Height int
+}
+
+func (v Viewport) Empty() bool {
+	return v.Width <= 0 || v.Height <= 0
+}
+
+func (v Viewport) Contains(p Point) bool {
+	return p.X >= v.X && p.X < v.X+v.Width && p.Y >= v.Y && p.Y < v.Y+v.Height
+}
+
+func (v Viewport) Overlaps(o Viewport) bool {
+	if v.Empty() || o.Empty() {
+		return false
+	}
+	return v.X < o.X+o.Width && o.X < v.X+v.Width && v.Y < o.Y+o.Height && o.Y < v.Y+v.Height
+}
+
+// grows the viewport just enough to contain the point
+func (v Viewport) Include(p Point) Viewport {
+	if v.Empty() {
+		return Viewport{p.X, p.Y, 1, 1}
+	}
+
+	minX, minY := min(v.X, p.X), min(v.Y, p.Y)
+	maxX, maxY := max(v.X+v.Width, p.X+1), max(v.Y+v.Height, p.Y+1)
+	return Viewport{minX, minY, maxX - minX, maxY - minY}
+}
+
+// the smallest viewport that contains all the points
+func BoundingBox(points []Point) Viewport {
+	return boundingBoxOf(slices.Values(points))
+}
+
+func boundingBoxOf(points iter.Seq[Point]) Viewport {
+	v := Viewport{}
+	for p := range points {
+		v = v.Include(p)
+	}
+	return v
+}
+
+func TestViewport() {
+	//	centered on the origin, so half of it is in negative coordinates
+	circle := VectorToRaster(NewCircle(0, 0, 5))
+	fmt.Println(DrawPoints(circle))
+
+	//	only the bottom right quarter
+	fmt.Println(DrawPointsIn(circle, Viewport{X: 0, Y: 0, Width: 6, Height: 6}))
+
+	//	a window bigger than the drawing leaves empty space around it
+	fmt.Println(DrawPointsIn(VectorToRaster(NewRectangle(4, 3)), Viewport{X: -2, Y: -1, Width: 8, Height: 5}))
+}
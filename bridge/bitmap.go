@@ -0,0 +1,156 @@
+package main
+
+import (
+	"image"
+	"strings"
+)
+
+// in memory pixel grid, grows to the right and downwards as pixels are set
+type Bitmap struct {
+	Width, Height int
+	pix           [][]bool
+}
+
+func NewBitmap(width, height int) *Bitmap {
+	b := &Bitmap{}
+	b.grow(width, height)
+	return b
+}
+
+func (b *Bitmap) grow(width, height int) {
+	if height > b.Height {
+		for len(b.pix) < height {
+			b.pix = append(b.pix, make([]bool, b.Width))
+		}
+		b.Height = height
+	}
+	if width > b.Width {
+		for y := range b.pix {
+			b.pix[y] = append(b.pix[y], make([]bool, width-len(b.pix[y]))...)
+		}
+		b.Width = width
+	}
+}
+
+// pixels left of or above the origin are clipped
+func (b *Bitmap) Set(x, y int) {
+	if x < 0 || y < 0 {
+		return
+	}
+	b.grow(x+1, y+1)
+	b.pix[y][x] = true
+}
+
+func (b *Bitmap) At(x, y int) bool {
+	if x < 0 || y < 0 || x >= b.Width || y >= b.Height {
+		return false
+	}
+	return b.pix[y][x]
+}
+
+func (b *Bitmap) Clone() *Bitmap {
+	c := NewBitmap(b.Width, b.Height)
+	for y := range b.pix {
+		copy(c.pix[y], b.pix[y])
+	}
+	return c
+}
+
+// bresenham's line algorithm
+func (b *Bitmap) Line(x1, y1, x2, y2 int) {
+	dx, dy := abs(x2-x1), -abs(y2-y1)
+	sx, sy := 1, 1
+	if x1 > x2 {
+		sx = -1
+	}
+	if y1 > y2 {
+		sy = -1
+	}
+
+	err := dx + dy
+	for {
+		b.Set(x1, y1)
+		if x1 == x2 && y1 == y2 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x1 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y1 += sy
+		}
+	}
+}
+
+// midpoint circle algorithm
+func (b *Bitmap) Circle(cx, cy, radius int) {
+	x, y := radius, 0
+	d := 1 - radius
+	for x >= y {
+		for _, p := range [][2]int{
+			{x, y}, {y, x}, {-y, x}, {-x, y},
+			{-x, -y}, {-y, -x}, {y, -x}, {x, -y},
+		} {
+			b.Set(cx+p[0], cy+p[1])
+		}
+
+		y++
+		if d < 0 {
+			d += 2*y + 1
+		} else {
+			x--
+			d += 2*(y-x) + 1
+		}
+	}
+}
+
+// outline of a size x size square with its top left corner at x, y
+func (b *Bitmap) Square(x, y, size int) {
+	if size < 1 {
+		return
+	}
+	s := size - 1
+	b.Line(x, y, x+s, y)
+	b.Line(x, y, x, y+s)
+	b.Line(x+s, y, x+s, y+s)
+	b.Line(x, y+s, x+s, y+s)
+}
+
+// the pixels which are set in only one of the two bitmaps
+func (b *Bitmap) Diff(other *Bitmap) []image.Point {
+	var diff []image.Point
+	for y := 0; y < max(b.Height, other.Height); y++ {
+		for x := 0; x < max(b.Width, other.Width); x++ {
+			if b.At(x, y) != other.At(x, y) {
+				diff = append(diff, image.Point{x, y})
+			}
+		}
+	}
+	return diff
+}
+
+// ascii art, set pixels are drawn as `*`
+func (b *Bitmap) String() string {
+	sb := strings.Builder{}
+	for _, row := range b.pix {
+		for _, set := range row {
+			if set {
+				sb.WriteRune('*')
+			} else {
+				sb.WriteRune(' ')
+			}
+		}
+		sb.WriteRune('\n')
+	}
+	return sb.String()
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -1,33 +1,173 @@
 package main
 
-import "fmt"
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
 
 type Renderer interface {
 	RenderCircle(radius float32)
 	RenderSquare(size int)
 }
 
-// vector renderer implementation
+// vector renderer implementation, writes an svg document to w
+// elements are kept until Close so that the header can hold the size of the whole drawing
 type VectorRenderer struct {
+	w             io.Writer
+	body          bytes.Buffer
+	width, height float32
 }
 
+// writes to stdout if w is nil
+func NewVectorRenderer(w io.Writer) *VectorRenderer {
+	return &VectorRenderer{w: w}
+}
+
+// shapes have no position, so they are drawn touching the top left corner
 func (v *VectorRenderer) RenderCircle(radius float32) {
-	fmt.Println("Drawing through vector a circle of radius ", radius)
+	v.element("circle", "cx", radius, "cy", radius, "r", radius)
+	v.extend(2*radius, 2*radius)
 }
 func (v *VectorRenderer) RenderSquare(size int) {
-	fmt.Println("Drawing through vector a square of size ", size)
+	s := float32(size)
+	v.element("rect", "x", 0, "y", 0, "width", s, "height", s)
+	v.extend(s, s)
+}
+
+func (v *VectorRenderer) RenderLine(from, to Point) {
+	v.element("line", "x1", from.X, "y1", from.Y, "x2", to.X, "y2", to.Y)
+	v.extendTo(from, to)
+}
+func (v *VectorRenderer) RenderPolygon(points []Point) {
+	pts := make([]string, 0, len(points))
+	for _, p := range points {
+		pts = append(pts, svgValue(p.X)+","+svgValue(p.Y))
+	}
+	v.element("polygon", "points", strings.Join(pts, " "))
+	v.extendTo(points...)
+}
+func (v *VectorRenderer) RenderPath(path Path) {
+	v.element("path", "d", path.String())
+	for _, s := range path {
+		if s.Op != ClosePath {
+			v.extendTo(s.To)
+		}
+	}
+}
+func (v *VectorRenderer) RenderText(at Point, text string) {
+	fmt.Fprintf(&v.body, "  <text x=\"%s\" y=\"%s\">%s</text>\n", svgValue(at.X), svgValue(at.Y), svgValue(text))
+	v.extendTo(at)
+}
+
+func (v *VectorRenderer) extendTo(points ...Point) {
+	for _, p := range points {
+		v.extend(p.X, p.Y)
+	}
+}
+
+func (v *VectorRenderer) extend(width, height float32) {
+	v.width = max(v.width, width)
+	v.height = max(v.height, height)
 }
 
-// raster render implementation
+// attrs are name, value pairs
+func (v *VectorRenderer) element(name string, attrs ...any) {
+	fmt.Fprintf(&v.body, "  <%s", name)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		fmt.Fprintf(&v.body, ` %s="%s"`, attrs[i], svgValue(attrs[i+1]))
+	}
+	v.body.WriteString(` fill="none" stroke="black"/>`)
+	v.body.WriteRune('\n')
+}
+
+func svgValue(value any) string {
+	switch val := value.(type) {
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32)
+	case int:
+		return strconv.Itoa(val)
+	}
+
+	sb := strings.Builder{}
+	xml.EscapeText(&sb, []byte(fmt.Sprint(value)))
+	return sb.String()
+}
+
+// writes the whole document and starts a new one
+func (v *VectorRenderer) Close() error {
+	w := v.w
+	if w == nil {
+		w = os.Stdout
+	}
+
+	//	a margin of 1 so that the strokes on the edges are not cut in half
+	width, height := svgValue(v.width+2), svgValue(v.height+2)
+	_, err := fmt.Fprintf(w,
+		"<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n"+
+			"<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%s\" height=\"%s\" viewBox=\"-1 -1 %s %s\">\n%s</svg>\n",
+		width, height, width, height, v.body.String())
+
+	v.body.Reset()
+	v.width, v.height = 0, 0
+	return err
+}
+
+// raster render implementation, draws into a pixel grid where every unit is Dpi pixels
 type RastererRenderer struct {
-	Dpi int
+	Dpi    int
+	bitmap *Bitmap
 }
 
+func (r *RastererRenderer) scale(v float32) int {
+	dpi := r.Dpi
+	if dpi < 1 {
+		dpi = 1
+	}
+	return int(math.Round(float64(v) * float64(dpi)))
+}
+
+func (r *RastererRenderer) canvas() *Bitmap {
+	if r.bitmap == nil {
+		r.bitmap = NewBitmap(0, 0)
+	}
+	return r.bitmap
+}
+
+// shapes have no position, so they are drawn touching the top left corner
 func (r *RastererRenderer) RenderCircle(radius float32) {
-	fmt.Println("Drawing through raster pixels for a circle of radius ", radius)
+	rad := r.scale(radius)
+	r.canvas().Circle(rad, rad, rad)
 }
 func (r *RastererRenderer) RenderSquare(size int) {
-	fmt.Println("Drawing through raster a square of size ", size)
+	r.canvas().Square(0, 0, r.scale(float32(size)))
+}
+
+func (r *RastererRenderer) RenderLine(from, to Point) {
+	r.canvas().Line(r.scale(from.X), r.scale(from.Y), r.scale(to.X), r.scale(to.Y))
+}
+func (r *RastererRenderer) RenderPolygon(points []Point) {
+	for i := range points {
+		r.RenderLine(points[i], points[(i+1)%len(points)])
+	}
+}
+
+// a copy of everything drawn so far
+func (r *RastererRenderer) Bitmap() *Bitmap {
+	return r.canvas().Clone()
+}
+
+func (r *RastererRenderer) String() string {
+	return r.canvas().String()
+}
+
+func (r *RastererRenderer) Reset() {
+	r.bitmap = nil
 }
 
 // circle implementation
@@ -67,19 +207,73 @@ func (s *Square) Draw() {
 
 func TestBridge() {
 	raster := RastererRenderer{Dpi: 10}
-	vector := VectorRenderer{}
+	vector := NewVectorRenderer(os.Stdout)
 
 	circle := NewCircle(&raster, 4)
 	circle.Draw()
 	circle.Resize(2)
 	circle.Draw()
 
-	vCircle := NewCircle(&vector, 7)
+	vCircle := NewCircle(vector, 7)
 	vCircle.Draw()
 
 	rSquare := NewSquare(&raster, 8)
-	vSquare := NewSquare(&vector, 3)
+	vSquare := NewSquare(vector, 3)
 
 	rSquare.Draw()
 	vSquare.Draw()
+	if err := vector.Close(); err != nil {
+		fmt.Println("error writing svg:", err)
+	}
+
+	TestRasterRenderer()
+	TestVectorRenderer()
+	TestCapabilities()
+	TestRecordingRenderer()
+	TestScene()
+	TestTerminalRenderer()
+}
+
+func TestRasterRenderer() {
+	raster := RastererRenderer{Dpi: 2}
+	NewCircle(&raster, 4).Draw()
+	NewSquare(&raster, 5).Draw()
+	fmt.Println(raster.String())
+
+	//	twice the size at half the dpi gives the exact same pixels
+	same := RastererRenderer{Dpi: 1}
+	NewCircle(&same, 8).Draw()
+	NewSquare(&same, 10).Draw()
+	fmt.Println("pixels that differ:", len(raster.Bitmap().Diff(same.Bitmap())))
+
+	other := RastererRenderer{Dpi: 1}
+	NewCircle(&other, 8).Draw()
+	NewSquare(&other, 9).Draw()
+	fmt.Println("pixels that differ:", raster.Bitmap().Diff(other.Bitmap()))
+}
+
+func TestVectorRenderer() {
+	buf := bytes.Buffer{}
+	vector := NewVectorRenderer(&buf)
+	NewCircle(vector, 2.5).Draw()
+	NewSquare(vector, 8).Draw()
+	if err := vector.Close(); err != nil {
+		fmt.Println("error writing svg:", err)
+		return
+	}
+	fmt.Print(buf.String())
+
+	//	the output should be well formed xml
+	d := xml.NewDecoder(&buf)
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			fmt.Println("svg is well formed")
+			return
+		}
+		if err != nil {
+			fmt.Println("svg is not well formed:", err)
+			return
+		}
+	}
 }
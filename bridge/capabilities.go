@@ -0,0 +1,247 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// optional capabilities a Renderer can have on top of circles and squares
+// shapes check for them and fall back to simpler primitives, so Renderer itself never has to change
+
+type Point struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+type LineRenderer interface {
+	RenderLine(from, to Point)
+}
+
+type PolygonRenderer interface {
+	RenderPolygon(points []Point)
+}
+
+type TextRenderer interface {
+	RenderText(at Point, text string)
+}
+
+type PathRenderer interface {
+	RenderPath(path Path)
+}
+
+var ErrNotSupported = errors.New("renderer cannot draw this")
+
+type PathOp int
+
+const (
+	MoveTo PathOp = iota
+	LineTo
+	ClosePath
+)
+
+type PathSegment struct {
+	Op PathOp `json:"op"`
+	To Point  `json:"to"` //	unused for ClosePath
+}
+
+type Path []PathSegment
+
+func (p Path) MoveTo(x, y float32) Path {
+	return append(p, PathSegment{MoveTo, Point{x, y}})
+}
+func (p Path) LineTo(x, y float32) Path {
+	return append(p, PathSegment{LineTo, Point{x, y}})
+}
+func (p Path) Close() Path {
+	return append(p, PathSegment{Op: ClosePath})
+}
+
+// svg path data, "M0 0 L10 0 Z"
+func (p Path) String() string {
+	sb := strings.Builder{}
+	for i, s := range p {
+		if i > 0 {
+			sb.WriteRune(' ')
+		}
+		switch s.Op {
+		case MoveTo:
+			fmt.Fprintf(&sb, "M%s %s", svgValue(s.To.X), svgValue(s.To.Y))
+		case LineTo:
+			fmt.Fprintf(&sb, "L%s %s", svgValue(s.To.X), svgValue(s.To.Y))
+		case ClosePath:
+			sb.WriteRune('Z')
+		}
+	}
+	return sb.String()
+}
+
+// lines are the simplest primitive, there is nothing left to fall back to
+func renderLine(r Renderer, from, to Point) error {
+	if lr, ok := r.(LineRenderer); ok {
+		lr.RenderLine(from, to)
+		return nil
+	}
+	return fmt.Errorf("%w: line, %T has no RenderLine", ErrNotSupported, r)
+}
+
+// polygons fall back to their edges
+func renderPolygon(r Renderer, points []Point) error {
+	if pr, ok := r.(PolygonRenderer); ok {
+		pr.RenderPolygon(points)
+		return nil
+	}
+
+	for i := range points {
+		if err := renderLine(r, points[i], points[(i+1)%len(points)]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderPolyline(r Renderer, points []Point) error {
+	for i := 1; i < len(points); i++ {
+		if err := renderLine(r, points[i-1], points[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// paths fall back to a polygon for every closed sub path and lines for the open ones
+func renderPath(r Renderer, path Path) error {
+	if pr, ok := r.(PathRenderer); ok {
+		pr.RenderPath(path)
+		return nil
+	}
+
+	var current []Point
+	flush := func() error {
+		err := renderPolyline(r, current)
+		current = nil
+		return err
+	}
+
+	for _, s := range path {
+		switch s.Op {
+		case MoveTo:
+			if err := flush(); err != nil {
+				return err
+			}
+			current = []Point{s.To}
+		case LineTo:
+			current = append(current, s.To)
+		case ClosePath:
+			if len(current) > 0 {
+				start := current[0]
+				if err := renderPolygon(r, current); err != nil {
+					return err
+				}
+				//	drawing carries on from the start of the closed sub path
+				current = []Point{start}
+			}
+		}
+	}
+	return flush()
+}
+
+func renderText(r Renderer, at Point, text string) error {
+	if tr, ok := r.(TextRenderer); ok {
+		tr.RenderText(at, text)
+		return nil
+	}
+	return fmt.Errorf("%w: text, %T has no RenderText", ErrNotSupported, r)
+}
+
+// triangle, drawn as a polygon
+type Triangle struct {
+	renderer Renderer
+	points   [3]Point
+}
+
+func NewTriangle(renderer Renderer, a, b, c Point) *Triangle {
+	return &Triangle{
+		renderer: renderer,
+		points:   [3]Point{a, b, c},
+	}
+}
+
+func (t *Triangle) Draw() error {
+	return renderPolygon(t.renderer, t.points[:])
+}
+
+type PathShape struct {
+	renderer Renderer
+	path     Path
+}
+
+func NewPathShape(renderer Renderer, path Path) *PathShape {
+	return &PathShape{
+		renderer: renderer,
+		path:     path,
+	}
+}
+
+func (p *PathShape) Draw() error {
+	return renderPath(p.renderer, p.path)
+}
+
+type Label struct {
+	renderer Renderer
+	at       Point
+	text     string
+}
+
+func NewLabel(renderer Renderer, at Point, text string) *Label {
+	return &Label{
+		renderer: renderer,
+		at:       at,
+		text:     text,
+	}
+}
+
+func (l *Label) Draw() error {
+	return renderText(l.renderer, l.at, l.text)
+}
+
+// only knows the two primitives every renderer has
+type basicRenderer struct{}
+
+func (b *basicRenderer) RenderCircle(radius float32) {
+	fmt.Println("Drawing a circle of radius", radius)
+}
+func (b *basicRenderer) RenderSquare(size int) {
+	fmt.Println("Drawing a square of size", size)
+}
+
+func TestCapabilities() {
+	arrow := Path{}.
+		MoveTo(0, 3).LineTo(6, 3).
+		MoveTo(4, 1).LineTo(6, 3).LineTo(4, 5)
+	box := Path{}.MoveTo(8, 0).LineTo(12, 0).LineTo(12, 6).LineTo(8, 6).Close()
+
+	//	vector renderer has every capability
+	vector := NewVectorRenderer(nil)
+	//	raster renderer has lines and polygons, paths fall back to them and text is not possible
+	raster := &RastererRenderer{Dpi: 2}
+	//	basic renderer has nothing
+	basic := &basicRenderer{}
+
+	for _, r := range []Renderer{vector, raster, basic} {
+		fmt.Printf("%T:\n", r)
+		for _, err := range []error{
+			NewTriangle(r, Point{0, 8}, Point{3, 14}, Point{6, 8}).Draw(),
+			NewPathShape(r, arrow).Draw(),
+			NewPathShape(r, box).Draw(),
+			NewLabel(r, Point{0, 16}, "a < b & c").Draw(),
+		} {
+			if err != nil {
+				fmt.Println(" ", err)
+			}
+		}
+	}
+
+	vector.Close()
+	fmt.Println(raster.String())
+}
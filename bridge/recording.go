@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+type DrawOp string
+
+const (
+	OpCircle  DrawOp = "circle"
+	OpSquare  DrawOp = "square"
+	OpLine    DrawOp = "line"
+	OpPolygon DrawOp = "polygon"
+	OpPath    DrawOp = "path"
+	OpText    DrawOp = "text"
+)
+
+// a single call made on a renderer, only the fields of that call are set
+type DrawCall struct {
+	Op     DrawOp  `json:"op"`
+	Radius float32 `json:"radius,omitempty"`
+	Size   int     `json:"size,omitempty"`
+	Points []Point `json:"points,omitempty"` //	line is from, to
+	Path   Path    `json:"path,omitempty"`
+	Text   string  `json:"text,omitempty"`
+}
+
+func (c DrawCall) String() string {
+	b, _ := json.Marshal(c)
+	return string(b)
+}
+
+type DisplayList []DrawCall
+
+// draws every call onto r, falling back to simpler primitives when r lacks a capability
+// calls which cannot be drawn are skipped and reported together
+func (d DisplayList) Replay(r Renderer) error {
+	var errs []error
+	for i, c := range d {
+		var err error
+		switch c.Op {
+		case OpCircle:
+			r.RenderCircle(c.Radius)
+		case OpSquare:
+			r.RenderSquare(c.Size)
+		case OpLine:
+			if len(c.Points) != 2 {
+				err = fmt.Errorf("line needs 2 points, has %d", len(c.Points))
+				break
+			}
+			err = renderLine(r, c.Points[0], c.Points[1])
+		case OpPolygon:
+			err = renderPolygon(r, c.Points)
+		case OpPath:
+			err = renderPath(r, c.Path)
+		case OpText:
+			if len(c.Points) != 1 {
+				err = fmt.Errorf("text needs 1 point, has %d", len(c.Points))
+				break
+			}
+			err = renderText(r, c.Points[0], c.Text)
+		default:
+			err = fmt.Errorf("unknown op %q", c.Op)
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("call %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d DisplayList) Save(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+func LoadDisplayList(r io.Reader) (DisplayList, error) {
+	var d DisplayList
+	if err := json.NewDecoder(r).Decode(&d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// every difference against the golden list, nothing means they are the same
+func (d DisplayList) Diff(golden DisplayList) []string {
+	var diff []string
+	for i := 0; i < max(len(d), len(golden)); i++ {
+		switch {
+		case i >= len(d):
+			diff = append(diff, fmt.Sprintf("call %d: missing %v", i, golden[i]))
+		case i >= len(golden):
+			diff = append(diff, fmt.Sprintf("call %d: unexpected %v", i, d[i]))
+		case !reflect.DeepEqual(d[i], golden[i]):
+			diff = append(diff, fmt.Sprintf("call %d: got %v, want %v", i, d[i], golden[i]))
+		}
+	}
+	return diff
+}
+
+// renderer which only records the calls made on it, it has every capability so nothing is lost
+type RecordingRenderer struct {
+	list DisplayList
+}
+
+func (r *RecordingRenderer) RenderCircle(radius float32) {
+	r.list = append(r.list, DrawCall{Op: OpCircle, Radius: radius})
+}
+func (r *RecordingRenderer) RenderSquare(size int) {
+	r.list = append(r.list, DrawCall{Op: OpSquare, Size: size})
+}
+func (r *RecordingRenderer) RenderLine(from, to Point) {
+	r.list = append(r.list, DrawCall{Op: OpLine, Points: []Point{from, to}})
+}
+func (r *RecordingRenderer) RenderPolygon(points []Point) {
+	r.list = append(r.list, DrawCall{Op: OpPolygon, Points: append([]Point(nil), points...)})
+}
+func (r *RecordingRenderer) RenderPath(path Path) {
+	r.list = append(r.list, DrawCall{Op: OpPath, Path: append(Path(nil), path...)})
+}
+func (r *RecordingRenderer) RenderText(at Point, text string) {
+	r.list = append(r.list, DrawCall{Op: OpText, Points: []Point{at}, Text: text})
+}
+
+// a copy of the calls recorded so far
+func (r *RecordingRenderer) List() DisplayList {
+	return append(DisplayList(nil), r.list...)
+}
+
+func (r *RecordingRenderer) Reset() {
+	r.list = nil
+}
+
+func TestRecordingRenderer() {
+	rec := &RecordingRenderer{}
+	NewCircle(rec, 3).Draw()
+	NewSquare(rec, 4).Draw()
+	NewTriangle(rec, Point{0, 0}, Point{2, 4}, Point{4, 0}).Draw()
+	NewLabel(rec, Point{0, 8}, "scene").Draw()
+
+	golden := DisplayList{
+		{Op: OpCircle, Radius: 3},
+		{Op: OpSquare, Size: 4},
+		{Op: OpPolygon, Points: []Point{{0, 0}, {2, 4}, {4, 0}}},
+		{Op: OpText, Points: []Point{{0, 8}}, Text: "scene"},
+	}
+	fmt.Println("differences from golden:", rec.List().Diff(golden))
+
+	//	survives a round trip through json
+	buf := bytes.Buffer{}
+	if err := rec.List().Save(&buf); err != nil {
+		fmt.Println("error saving:", err)
+		return
+	}
+	fmt.Print(buf.String())
+	loaded, err := LoadDisplayList(&buf)
+	if err != nil {
+		fmt.Println("error loading:", err)
+		return
+	}
+	fmt.Println("differences after loading:", loaded.Diff(golden))
+
+	//	the same scene replayed onto the other renderers
+	vector := NewVectorRenderer(nil)
+	if err := loaded.Replay(vector); err != nil {
+		fmt.Println(err)
+	}
+	vector.Close()
+
+	raster := &RastererRenderer{Dpi: 1}
+	if err := loaded.Replay(raster); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(raster.String())
+
+	changed := append(loaded[:1:1], DrawCall{Op: OpSquare, Size: 5})
+	fmt.Println("differences from golden:", changed.Diff(golden))
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// 2d affine transform, the same as svg's matrix(a b c d e f)
+//
+//	x' = A*x + C*y + E
+//	y' = B*x + D*y + F
+type Transform struct {
+	A, B, C, D, E, F float32
+}
+
+func Identity() Transform {
+	return Transform{A: 1, D: 1}
+}
+
+func Translate(x, y float32) Transform {
+	return Transform{A: 1, D: 1, E: x, F: y}
+}
+
+func Scale(sx, sy float32) Transform {
+	return Transform{A: sx, D: sy}
+}
+
+// clockwise, as y grows downwards
+func Rotate(deg float32) Transform {
+	rad := float64(deg) * math.Pi / 180
+	sin, cos := float32(math.Sin(rad)), float32(math.Cos(rad))
+	return Transform{A: cos, B: sin, C: -sin, D: cos}
+}
+
+// t.Mul(o) applies o first and then t
+func (t Transform) Mul(o Transform) Transform {
+	return Transform{
+		A: t.A*o.A + t.C*o.B,
+		B: t.B*o.A + t.D*o.B,
+		C: t.A*o.C + t.C*o.D,
+		D: t.B*o.C + t.D*o.D,
+		E: t.A*o.E + t.C*o.F + t.E,
+		F: t.B*o.E + t.D*o.F + t.F,
+	}
+}
+
+func (t Transform) Apply(p Point) Point {
+	return Point{
+		X: t.A*p.X + t.C*p.Y + t.E,
+		Y: t.B*p.X + t.D*p.Y + t.F,
+	}
+}
+
+func (t Transform) applyAll(points []Point) []Point {
+	out := make([]Point, len(points))
+	for i, p := range points {
+		out[i] = t.Apply(p)
+	}
+	return out
+}
+
+// anything that can be put into a scene, world is the transform of everything above it
+type Node interface {
+	render(r Renderer, world Transform) error
+}
+
+// group of nodes sharing a transform, groups can be nested
+type Group struct {
+	transform Transform
+	children  []Node
+}
+
+func NewGroup(children ...Node) *Group {
+	return &Group{
+		transform: Identity(),
+		children:  children,
+	}
+}
+
+func (g *Group) Add(children ...Node) *Group {
+	g.children = append(g.children, children...)
+	return g
+}
+
+// transforms are applied like in svg, the one added last is applied to the children first
+func (g *Group) Translate(x, y float32) *Group {
+	g.transform = g.transform.Mul(Translate(x, y))
+	return g
+}
+func (g *Group) Scale(sx, sy float32) *Group {
+	g.transform = g.transform.Mul(Scale(sx, sy))
+	return g
+}
+func (g *Group) Rotate(deg float32) *Group {
+	g.transform = g.transform.Mul(Rotate(deg))
+	return g
+}
+
+func (g *Group) render(r Renderer, world Transform) error {
+	world = world.Mul(g.transform)
+
+	var errs []error
+	for _, c := range g.children {
+		if err := c.render(r, world); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// the scale of a transform which only scales evenly, the one case a plain Renderer can draw
+// as it has no way to place, rotate or stretch a shape
+func (t Transform) evenScale() (float32, bool) {
+	return t.A, t.B == 0 && t.C == 0 && t.E == 0 && t.F == 0 && t.A == t.D && t.A > 0
+}
+
+func canDrawLines(r Renderer) bool {
+	_, lines := r.(LineRenderer)
+	_, polygons := r.(PolygonRenderer)
+	return lines || polygons
+}
+
+// a Circle is placed like RenderCircle draws it, touching the top left corner of its group.
+// it can turn into an ellipse once scaled, so it goes out as a polygon when the renderer draws lines
+func (c *Circle) render(r Renderer, world Transform) error {
+	if scale, ok := world.evenScale(); ok && !canDrawLines(r) {
+		r.RenderCircle(c.radius * scale)
+		return nil
+	}
+
+	//	about one segment for every unit of the outline
+	segments := max(24, int(math.Ceil(2*math.Pi*float64(c.radius))))
+	points := make([]Point, segments)
+	for i := range points {
+		rad := 2 * math.Pi * float64(i) / float64(segments)
+		points[i] = Point{
+			X: c.radius + c.radius*float32(math.Cos(rad)),
+			Y: c.radius + c.radius*float32(math.Sin(rad)),
+		}
+	}
+	return renderPolygon(r, world.applyAll(points))
+}
+
+// the top left corner of a Square is the origin of its group
+func (s *Square) render(r Renderer, world Transform) error {
+	if scale, ok := world.evenScale(); ok && !canDrawLines(r) {
+		r.RenderSquare(int(math.Round(float64(float32(s.size) * scale))))
+		return nil
+	}
+
+	size := float32(s.size)
+	return renderPolygon(r, world.applyAll([]Point{{0, 0}, {size, 0}, {size, size}, {0, size}}))
+}
+
+type PolygonNode struct {
+	Points []Point
+}
+
+func (p *PolygonNode) render(r Renderer, world Transform) error {
+	return renderPolygon(r, world.applyAll(p.Points))
+}
+
+// only the position of text is transformed
+type TextNode struct {
+	At   Point
+	Text string
+}
+
+func (t *TextNode) render(r Renderer, world Transform) error {
+	return renderText(r, world.Apply(t.At), t.Text)
+}
+
+// scene graph, switching the renderer switches it for the whole composition.
+// shapes that are moved, rotated or stretched are drawn as lines, which needs a LineRenderer
+// or PolygonRenderer, with only RenderCircle and RenderSquare they fail with ErrNotSupported
+type Scene struct {
+	Root     *Group
+	renderer Renderer
+}
+
+func NewScene(renderer Renderer) *Scene {
+	return &Scene{
+		Root:     NewGroup(),
+		renderer: renderer,
+	}
+}
+
+func (s *Scene) SetRenderer(renderer Renderer) {
+	s.renderer = renderer
+}
+
+func (s *Scene) Draw() error {
+	return s.Root.render(s.renderer, Identity())
+}
+
+func TestScene() {
+	house := NewGroup(
+		NewGroup(NewSquare(nil, 8)).Translate(0, 4),
+		&PolygonNode{Points: []Point{{0, 4}, {4, 0}, {8, 4}}},
+	)
+
+	raster := &RastererRenderer{Dpi: 1}
+	scene := NewScene(raster)
+	scene.Root.Add(
+		house,
+		NewGroup(house).Translate(10, 0),
+		//	the same house, one and a half times the size and tipped over
+		NewGroup(house).Translate(40, 2).Rotate(90).Scale(1.5, 1.5),
+		NewGroup(NewCircle(nil, 4)).Translate(0, 17).Scale(1, 0.5),
+		&TextNode{At: Point{0, 24}, Text: "village"},
+	)
+
+	if err := scene.Draw(); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(raster.String())
+
+	//	same composition, different renderer
+	vector := NewVectorRenderer(nil)
+	scene.SetRenderer(vector)
+	if err := scene.Draw(); err != nil {
+		fmt.Println(err)
+	}
+	vector.Close()
+
+	//	a renderer with only the two primitives can still draw shapes that are just scaled
+	basic := NewScene(&basicRenderer{})
+	basic.Root.Add(
+		NewGroup(NewCircle(nil, 2), NewSquare(nil, 3)).Scale(2, 2),
+		NewGroup(NewSquare(nil, 3)).Translate(5, 0),
+	)
+	fmt.Println(basic.Draw())
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+type ANSIColor int
+
+const (
+	DefaultColor ANSIColor = 0
+	Red          ANSIColor = 31
+	Green        ANSIColor = 32
+	Yellow       ANSIColor = 33
+	Blue         ANSIColor = 34
+	Magenta      ANSIColor = 35
+	Cyan         ANSIColor = 36
+)
+
+type termCell struct {
+	r     rune
+	color ANSIColor
+}
+
+// terminal render implementation, squares are drawn with box characters and everything else with braille dots
+// one unit is one character wide and half a character tall, so shapes are not squashed
+type TerminalRenderer struct {
+	w       io.Writer
+	NoColor bool
+
+	color  ANSIColor
+	dots   *Bitmap              //	a character holds 2x4 braille dots
+	tint   map[[2]int]ANSIColor //	colour of the dots in a character
+	cells  map[[2]int]termCell  //	box and text characters, these win over dots
+	width  int
+	height int
+}
+
+// writes to stdout if w is nil, which is also what the zero value does
+func NewTerminalRenderer(w io.Writer) *TerminalRenderer {
+	return &TerminalRenderer{w: w}
+}
+
+// made on first use, so a TerminalRenderer literal works without the constructor
+func (t *TerminalRenderer) canvas() *Bitmap {
+	if t.dots == nil {
+		t.dots = NewBitmap(0, 0)
+		t.tint = map[[2]int]ANSIColor{}
+		t.cells = map[[2]int]termCell{}
+	}
+	return t.dots
+}
+
+// colour of everything drawn from now on
+func (t *TerminalRenderer) SetColor(c ANSIColor) {
+	t.color = c
+}
+
+func (t *TerminalRenderer) toDots(v float32) int {
+	return int(math.Round(float64(v) * 2))
+}
+
+func (t *TerminalRenderer) dot(x, y int) {
+	if x < 0 || y < 0 {
+		return
+	}
+	t.canvas().Set(x, y)
+	t.tint[[2]int{x / 2, y / 4}] = t.color
+}
+
+func (t *TerminalRenderer) cell(x, y int, r rune) {
+	if x < 0 || y < 0 {
+		return
+	}
+	t.canvas()
+	t.cells[[2]int{x, y}] = termCell{r, t.color}
+	t.width = max(t.width, x+1)
+	t.height = max(t.height, y+1)
+}
+
+// drawn into a scratch bitmap first, so that only the new dots get the current colour
+func (t *TerminalRenderer) plot(draw func(b *Bitmap)) {
+	scratch := NewBitmap(0, 0)
+	draw(scratch)
+	for y := 0; y < scratch.Height; y++ {
+		for x := 0; x < scratch.Width; x++ {
+			if scratch.At(x, y) {
+				t.dot(x, y)
+			}
+		}
+	}
+}
+
+// shapes have no position, so they are drawn touching the top left corner
+func (t *TerminalRenderer) RenderCircle(radius float32) {
+	r := t.toDots(radius)
+	t.plot(func(b *Bitmap) { b.Circle(r, r, r) })
+}
+
+func (t *TerminalRenderer) RenderSquare(size int) {
+	if size < 2 {
+		size = 2
+	}
+	w, h := size, max(2, int(math.Round(float64(size)/2)))
+
+	for x := 1; x < w-1; x++ {
+		t.cell(x, 0, '─')
+		t.cell(x, h-1, '─')
+	}
+	for y := 1; y < h-1; y++ {
+		t.cell(0, y, '│')
+		t.cell(w-1, y, '│')
+	}
+	t.cell(0, 0, '┌')
+	t.cell(w-1, 0, '┐')
+	t.cell(0, h-1, '└')
+	t.cell(w-1, h-1, '┘')
+}
+
+func (t *TerminalRenderer) RenderLine(from, to Point) {
+	t.plot(func(b *Bitmap) {
+		b.Line(t.toDots(from.X), t.toDots(from.Y), t.toDots(to.X), t.toDots(to.Y))
+	})
+}
+
+func (t *TerminalRenderer) RenderPolygon(points []Point) {
+	for i := range points {
+		t.RenderLine(points[i], points[(i+1)%len(points)])
+	}
+}
+
+func (t *TerminalRenderer) RenderText(at Point, text string) {
+	x, y := int(math.Round(float64(at.X))), int(math.Round(float64(at.Y)/2))
+	for i, r := range []rune(text) {
+		t.cell(x+i, y, r)
+	}
+}
+
+// braille dot numbering, the bit of the dot at x, y inside a character
+var brailleBits = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+func (t *TerminalRenderer) at(x, y int) termCell {
+	if c, ok := t.cells[[2]int{x, y}]; ok {
+		return c
+	}
+
+	var bits rune
+	for dy := 0; dy < 4; dy++ {
+		for dx := 0; dx < 2; dx++ {
+			if t.dots.At(x*2+dx, y*4+dy) {
+				bits |= brailleBits[dy][dx]
+			}
+		}
+	}
+	if bits == 0 {
+		return termCell{' ', DefaultColor}
+	}
+	return termCell{0x2800 + bits, t.tint[[2]int{x, y}]}
+}
+
+// writes everything drawn so far and starts again with an empty screen
+func (t *TerminalRenderer) Flush() error {
+	w := t.w
+	if w == nil {
+		w = os.Stdout
+	}
+	bw := bufio.NewWriter(w)
+
+	dots := t.canvas()
+	width := max(t.width, (dots.Width+1)/2)
+	height := max(t.height, (dots.Height+3)/4)
+	for y := 0; y < height; y++ {
+		current := DefaultColor
+		for x := 0; x < width; x++ {
+			c := t.at(x, y)
+			if !t.NoColor && c.color != current && c.r != ' ' {
+				fmt.Fprintf(bw, "\x1b[%dm", c.color)
+				current = c.color
+			}
+			bw.WriteRune(c.r)
+		}
+		if current != DefaultColor {
+			bw.WriteString("\x1b[0m")
+		}
+		bw.WriteRune('\n')
+	}
+
+	t.dots, t.tint, t.cells = nil, nil, nil
+	t.width, t.height = 0, 0
+	return bw.Flush()
+}
+
+func TestTerminalRenderer() {
+	term := NewTerminalRenderer(os.Stdout)
+
+	term.SetColor(Cyan)
+	NewCircle(term, 6).Draw()
+	term.SetColor(Yellow)
+	NewSquare(term, 8).Draw()
+	term.SetColor(Magenta)
+	NewTriangle(term, Point{14, 12}, Point{20, 0}, Point{26, 12}).Draw()
+	term.SetColor(DefaultColor)
+	NewLabel(term, Point{14, 14}, "preview").Draw()
+	if err := term.Flush(); err != nil {
+		fmt.Println("error writing to the terminal:", err)
+	}
+
+	//	the scene graph works with it too, and so does the zero value which writes to stdout
+	preview := &TerminalRenderer{NoColor: true}
+	scene := NewScene(preview)
+	scene.Root.Add(NewGroup(NewSquare(nil, 6)).Translate(4, 1).Rotate(45))
+	if err := scene.Draw(); err != nil {
+		fmt.Println(err)
+	}
+	if err := preview.Flush(); err != nil {
+		fmt.Println("error writing to the terminal:", err)
+	}
+}
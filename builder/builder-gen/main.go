@@ -0,0 +1,377 @@
+// builder-gen writes a fluent builder for a struct, in the style of EmailBuilder and the facet builders.
+//
+//	//go:generate go run ./builder-gen -type Contact -output contact-builder.go
+//
+// fields are configured with the `builder` struct tag, options are separated by commas:
+//
+//	required        Build fails if the field was never set
+//	validate=NAME   checked whenever the field is set, one of nonempty, email, positive
+//	facet=NAME      the setter goes on a separate facet builder, like PersonAddressBuilder
+//	method=NAME     name of the setter, defaults to the field name
+//	-               no setter for the field
+//
+// with -check nothing is written, the output is compared with the existing file instead,
+// the golden files in testdata are compared the same way by go test
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type field struct {
+	name      string //	name in the struct
+	method    string
+	typ       string
+	required  bool
+	validator string
+	facet     string
+	imports   []string //	import specs the type needs, like "time" or pb "example.com/proto"
+}
+
+type structInfo struct {
+	pkg    string
+	name   string
+	fields []field
+}
+
+var numberTypes = []string{
+	"int", "int8", "int16", "int32", "int64",
+	"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+	"float32", "float64", "byte", "rune",
+}
+
+var validators = map[string]struct {
+	check   string //	if clause which is true when the value is invalid, v is the value
+	message string
+	imports []string
+	types   []string //	field types the check compiles for
+}{
+	"nonempty": {`strings.TrimSpace(v) == ""`, "should not be empty", []string{"strings"}, []string{"string"}},
+	"email":    {`_, err := mail.ParseAddress(v); err != nil`, "should be an email address", []string{"net/mail"}, []string{"string"}},
+	"positive": {`v <= 0`, "should be positive", nil, numberTypes},
+}
+
+func exported(name string) string {
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// finds the struct in the go files of dir
+func load(dir, typeName string) (*structInfo, error) {
+	fset := token.NewFileSet()
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range matches {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				st, ok := ts.Type.(*ast.StructType)
+				if ts.Name.Name != typeName || !ok {
+					continue
+				}
+				info := &structInfo{pkg: file.Name.Name, name: typeName}
+				if err := info.addFields(fset, fileImports(file), st); err != nil {
+					return nil, err
+				}
+				return info, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("struct %s not found in %s", typeName, dir)
+}
+
+// import specs of a file by the name they are used with
+func fileImports(file *ast.File) map[string]string {
+	imports := map[string]string{}
+	for _, imp := range file.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		if imp.Name != nil {
+			if imp.Name.Name != "_" && imp.Name.Name != "." {
+				imports[imp.Name.Name] = imp.Name.Name + " " + imp.Path.Value
+			}
+			continue
+		}
+		//	the package name is usually the last element, gopkg.in/yaml.v3 and example.com/x/v2 are not
+		name := path.Base(importPath)
+		if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+			name = path.Base(path.Dir(importPath))
+		}
+		name, _, _ = strings.Cut(name, ".")
+		name = strings.TrimPrefix(name, "go-")
+		imports[name] = imp.Path.Value
+	}
+	return imports
+}
+
+// the imports of the file which the type refers to, like time for time.Time
+func typeImports(typ ast.Expr, imports map[string]string) []string {
+	var needed []string
+	ast.Inspect(typ, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if id, ok := sel.X.(*ast.Ident); ok {
+			if spec, ok := imports[id.Name]; ok && !slices.Contains(needed, spec) {
+				needed = append(needed, spec)
+			}
+		}
+		return false
+	})
+	return needed
+}
+
+func (s *structInfo) addFields(fset *token.FileSet, imports map[string]string, st *ast.StructType) error {
+	for _, f := range st.Fields.List {
+		typ := bytes.Buffer{}
+		if err := printer.Fprint(&typ, fset, f.Type); err != nil {
+			return err
+		}
+
+		tag := ""
+		if f.Tag != nil {
+			tag = reflect.StructTag(strings.Trim(f.Tag.Value, "`")).Get("builder")
+		}
+		if tag == "-" {
+			continue
+		}
+
+		//	embedded fields are skipped, there is no sensible setter name for them
+		for _, name := range f.Names {
+			fd := field{name: name.Name, method: exported(name.Name), typ: typ.String(), imports: typeImports(f.Type, imports)}
+			for _, opt := range strings.Split(tag, ",") {
+				key, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+				switch key {
+				case "":
+				case "required":
+					fd.required = true
+				case "validate":
+					v, ok := validators[value]
+					if !ok {
+						return fmt.Errorf("%s.%s: unknown validator %q", s.name, name.Name, value)
+					}
+					if !slices.Contains(v.types, fd.typ) {
+						return fmt.Errorf("%s.%s: validator %q does not work on %s, only on %s",
+							s.name, name.Name, value, fd.typ, strings.Join(v.types, ", "))
+					}
+					fd.validator = value
+				case "facet":
+					fd.facet = value
+				case "method":
+					fd.method = value
+				default:
+					return fmt.Errorf("%s.%s: unknown option %q", s.name, name.Name, key)
+				}
+			}
+			s.fields = append(s.fields, fd)
+		}
+	}
+	return nil
+}
+
+func (s *structInfo) facets() []string {
+	var facets []string
+	for _, f := range s.fields {
+		if f.facet != "" && !slices.Contains(facets, f.facet) {
+			facets = append(facets, f.facet)
+		}
+	}
+	return facets
+}
+
+func generate(s *structInfo) ([]byte, error) {
+	b := s.name + "Builder"
+	//	import specs as they are written in the import block, quotes included
+	imports := []string{`"errors"`}
+	use := func(imp string) {
+		if !slices.Contains(imports, imp) {
+			imports = append(imports, imp)
+		}
+	}
+	body := bytes.Buffer{}
+	p := func(format string, args ...any) {
+		fmt.Fprintf(&body, format, args...)
+		body.WriteByte('\n')
+	}
+
+	p("type %s struct {", b)
+	p("value %s", s.name)
+	p("errs []error")
+	p("given map[string]bool //	set even when the value was invalid, so it is not reported twice")
+	p("}")
+	p("")
+	p("func New%s() *%s {", b, b)
+	p("return &%s{given: map[string]bool{}}", b)
+	p("}")
+
+	for _, facet := range s.facets() {
+		fb := s.name + exported(facet) + "Builder"
+		p("")
+		p("type %s struct {", fb)
+		p("*%s", b)
+		p("}")
+		p("")
+		p("func (b *%s) %s() *%s {", b, exported(facet), fb)
+		p("return &%s{%s: b}", fb, b)
+		p("}")
+	}
+
+	for _, f := range s.fields {
+		for _, imp := range f.imports {
+			use(imp)
+		}
+		p("")
+		p("func (b *%s) set%s(v %s) {", b, exported(f.name), f.typ)
+		p("b.given[%q] = true", exported(f.name))
+		if f.validator != "" {
+			v := validators[f.validator]
+			for _, imp := range v.imports {
+				use(strconv.Quote(imp))
+			}
+			use(`"fmt"`)
+			p("if %s {", v.check)
+			verb := "%v"
+			if f.typ == "string" {
+				verb = "%q"
+			}
+			p("b.errs = append(b.errs, fmt.Errorf(\"%s %s, got %s\", v))", exported(f.name), v.message, verb)
+			p("return")
+			p("}")
+		}
+		p("b.value.%s = v", f.name)
+		p("}")
+
+		owner := b
+		if f.facet != "" {
+			owner = s.name + exported(f.facet) + "Builder"
+		}
+		//	the parameter is always v, a name taken from the method can be a keyword like type or range
+		p("func (b *%s) %s(v %s) *%s {", owner, f.method, f.typ, owner)
+		if f.facet != "" {
+			p("b.%s.set%s(v)", b, exported(f.name))
+		} else {
+			p("b.set%s(v)", exported(f.name))
+		}
+		p("return b")
+		p("}")
+	}
+
+	var required []string
+	for _, f := range s.fields {
+		if f.required {
+			required = append(required, fmt.Sprintf("%q", exported(f.name)))
+		}
+	}
+	p("")
+	p("// checks the required fields, every problem is reported together")
+	p("func (b *%s) Build() (%s, error) {", b, s.name)
+	p("errs := append([]error(nil), b.errs...)")
+	if len(required) > 0 {
+		use(`"fmt"`)
+		p("for _, f := range []string{%s} {", strings.Join(required, ", "))
+		p("if !b.given[f] {")
+		p("errs = append(errs, fmt.Errorf(\"%%s is required\", f))")
+		p("}")
+		p("}")
+	}
+	p("if len(errs) > 0 {")
+	p("return %s{}, errors.Join(errs...)", s.name)
+	p("}")
+	p("return b.value, nil")
+	p("}")
+
+	out := bytes.Buffer{}
+	fmt.Fprintf(&out, "// Code generated by builder-gen; DO NOT EDIT.\n\npackage %s\n\nimport (\n", s.pkg)
+	slices.Sort(imports)
+	for _, imp := range imports {
+		fmt.Fprintf(&out, "%s\n", imp)
+	}
+	out.WriteString(")\n\n")
+	out.Write(body.Bytes())
+
+	return format.Source(out.Bytes())
+}
+
+func main() {
+	typeName := flag.String("type", "", "name of the struct to generate a builder for")
+	output := flag.String("output", "", "file to write, defaults to <type>-builder.go in the source directory")
+	dir := flag.String("dir", ".", "directory of the package the struct is in")
+	check := flag.Bool("check", false, "compare with the output file instead of writing it")
+	flag.Parse()
+
+	if *typeName == "" {
+		fmt.Fprintln(os.Stderr, "builder-gen: -type is required")
+		os.Exit(2)
+	}
+	if *output == "" {
+		*output = strings.ToLower(*typeName) + "-builder.go"
+	}
+	path := *output
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(*dir, path)
+	}
+
+	info, err := load(*dir, *typeName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "builder-gen:", err)
+		os.Exit(1)
+	}
+	src, err := generate(info)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "builder-gen:", err)
+		os.Exit(1)
+	}
+
+	if *check {
+		existing, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "builder-gen:", err)
+			os.Exit(1)
+		}
+		if !bytes.Equal(existing, src) {
+			fmt.Fprintf(os.Stderr, "builder-gen: %s is out of date\n", path)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := os.WriteFile(path, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "builder-gen:", err)
+		os.Exit(1)
+	}
+}
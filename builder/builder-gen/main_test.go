@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// every struct in testdata has a golden file named after it, regenerate them with
+//
+//	go run . -dir testdata -type Account -output account.golden
+var goldenTypes = []string{"Account", "Plain", "Keywords", "Event"}
+
+func TestGolden(t *testing.T) {
+	for _, typeName := range goldenTypes {
+		t.Run(typeName, func(t *testing.T) {
+			info, err := load("testdata", typeName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := generate(info)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := os.ReadFile(filepath.Join("testdata", strings.ToLower(typeName)+".golden"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("output differs from the golden file:\n%s", got)
+			}
+		})
+	}
+}
+
+// the golden files are type checked together with the structs, so an unused import
+// or a setter which does not parse fails here and not in the package using the builder
+func TestGoldenCompiles(t *testing.T) {
+	fset := token.NewFileSet()
+	sources, err := filepath.Glob(filepath.Join("testdata", "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, typeName := range goldenTypes {
+		sources = append(sources, filepath.Join("testdata", strings.ToLower(typeName)+".golden"))
+	}
+
+	var files []*ast.File
+	for _, path := range sources {
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("testdata", fset, files, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// a validator on a type it does not compile for is an error and not a broken builder
+func TestValidatorTypes(t *testing.T) {
+	for _, tc := range []struct {
+		field string
+		ok    bool
+	}{
+		{"Name string `builder:\"validate=positive\"`", false},
+		{"Count int `builder:\"validate=nonempty\"`", false},
+		{"Count int `builder:\"validate=email\"`", false},
+		{"Count int64 `builder:\"validate=positive\"`", true},
+		{"Name string `builder:\"validate=email\"`", true},
+	} {
+		dir := t.TempDir()
+		src := "package x\n\ntype X struct {\n" + tc.field + "\n}\n"
+		if err := os.WriteFile(filepath.Join(dir, "x.go"), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := load(dir, "X")
+		if tc.ok && err != nil {
+			t.Errorf("%s: %v", tc.field, err)
+		}
+		if !tc.ok && err == nil {
+			t.Errorf("%s: expected an error", tc.field)
+		}
+	}
+}
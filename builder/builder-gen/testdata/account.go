@@ -0,0 +1,11 @@
+package testdata
+
+type Account struct {
+	Owner    string   `builder:"required,validate=nonempty"`
+	Email    string   `builder:"required,validate=email"`
+	Balance  int      `builder:"validate=positive"`
+	Tags     []string `builder:"method=TaggedWith"`
+	street   string   `builder:"facet=address,method=At"`
+	city     string   `builder:"facet=address,required"`
+	internal bool     `builder:"-"`
+}
@@ -0,0 +1,15 @@
+package testdata
+
+import (
+	"net/url"
+	tm "time"
+)
+
+// field types from other packages, their imports have to be carried into the builder
+type Event struct {
+	Title string `builder:"required,validate=nonempty"`
+	When  tm.Time
+	Every []tm.Duration
+	Link  *url.URL
+	Seats uint16 `builder:"validate=positive"`
+}
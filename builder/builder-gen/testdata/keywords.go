@@ -0,0 +1,11 @@
+package testdata
+
+// setter names which are go keywords or the receiver name
+type Keywords struct {
+	Type    string `builder:"required"`
+	Range   []int
+	Func    func() error
+	Map     map[string]string
+	Default string `builder:"facet=fallback"`
+	B       bool
+}
@@ -0,0 +1,7 @@
+package testdata
+
+// no builder tags, so the generated code needs neither fmt nor a required check
+type Plain struct {
+	Name  string
+	Count int
+}
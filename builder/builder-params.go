@@ -1,73 +1,371 @@
 package main
 
 import (
+	"bytes"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 )
 
+type attachment struct {
+	filename    string
+	contentType string
+	data        []byte
+}
+
 type email struct {
-	from    string
-	to      string
-	subject string
-	body    string
+	from        string
+	to          []string
+	cc          []string
+	bcc         []string //	never written into the message, only used when sending
+	subject     string
+	body        string //	plain text part
+	html        string
+	headers     textproto.MIMEHeader
+	attachments []attachment
+	date        time.Time
 }
 
-type EmailBuilder struct {
-	email email
-	err   error
+// everyone the mail has to be delivered to
+func (e *email) recipients() []string {
+	var all []string
+	for _, addrs := range [][]string{e.to, e.cc, e.bcc} {
+		all = append(all, addrs...)
+	}
+	return all
 }
 
-func (b *EmailBuilder) From(from string) *EmailBuilder {
-	if b.err != nil {
-		return b
+// bare addresses for the smtp envelope, without display names
+func (e *email) envelope() (string, []string) {
+	bare := func(addr string) string {
+		if a, err := mail.ParseAddress(addr); err == nil {
+			return a.Address
+		}
+		return addr
 	}
-	if !strings.Contains(from, "@") {
-		b.err = fmt.Errorf("From should contain an @")
-		return b
+
+	to := []string{}
+	for _, addr := range e.recipients() {
+		to = append(to, bare(addr))
 	}
-	b.email.from = from
-	return b
+	return bare(e.from), to
 }
-func (b *EmailBuilder) To(to string) *EmailBuilder {
-	if b.err != nil {
-		return b
+
+type EmailBuilder struct {
+	email     email
+	errs      []error //	every problem is kept, not just the first one
+	fromGiven bool
+}
+
+func (b *EmailBuilder) address(field, addr string) (string, bool) {
+	a, err := mail.ParseAddress(addr)
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("%s address %q is invalid: %w", field, addr, err))
+		return "", false
 	}
-	if !strings.Contains(to, "@") {
-		b.err = fmt.Errorf("From should contain an @")
-		return b
+	return a.String(), true
+}
+
+func (b *EmailBuilder) addresses(field string, addrs []string) []string {
+	var valid []string
+	for _, addr := range addrs {
+		if a, ok := b.address(field, addr); ok {
+			valid = append(valid, a)
+		}
+	}
+	return valid
+}
+
+func (b *EmailBuilder) From(from string) *EmailBuilder {
+	b.fromGiven = true
+	if a, ok := b.address("From", from); ok {
+		b.email.from = a
 	}
-	b.email.to = to
+	return b
+}
+func (b *EmailBuilder) To(to ...string) *EmailBuilder {
+	b.email.to = append(b.email.to, b.addresses("To", to)...)
+	return b
+}
+func (b *EmailBuilder) Cc(cc ...string) *EmailBuilder {
+	b.email.cc = append(b.email.cc, b.addresses("Cc", cc)...)
+	return b
+}
+func (b *EmailBuilder) Bcc(bcc ...string) *EmailBuilder {
+	b.email.bcc = append(b.email.bcc, b.addresses("Bcc", bcc)...)
 	return b
 }
 func (b *EmailBuilder) Subject(subject string) *EmailBuilder {
-	if b.err != nil {
+	if strings.ContainsAny(subject, "\r\n") {
+		b.errs = append(b.errs, fmt.Errorf("Subject should not contain line breaks"))
 		return b
 	}
 	b.email.subject = subject
 	return b
 }
 func (b *EmailBuilder) Body(body string) *EmailBuilder {
-	if b.err != nil {
+	b.email.body = body
+	return b
+}
+func (b *EmailBuilder) HTML(html string) *EmailBuilder {
+	b.email.html = html
+	return b
+}
+func (b *EmailBuilder) Date(date time.Time) *EmailBuilder {
+	b.email.date = date
+	return b
+}
+
+// extra header, the ones the builder writes itself cant be overridden
+func (b *EmailBuilder) Header(name, value string) *EmailBuilder {
+	key := textproto.CanonicalMIMEHeaderKey(name)
+	switch {
+	case strings.ContainsAny(name, "\r\n: ") || name == "":
+		b.errs = append(b.errs, fmt.Errorf("header name %q is invalid", name))
+	case strings.ContainsAny(value, "\r\n"):
+		b.errs = append(b.errs, fmt.Errorf("header %s should not contain line breaks", key))
+	case reservedHeaders[key]:
+		b.errs = append(b.errs, fmt.Errorf("header %s is set by the builder", key))
+	default:
+		if b.email.headers == nil {
+			b.email.headers = textproto.MIMEHeader{}
+		}
+		b.email.headers.Add(key, value)
+	}
+	return b
+}
+
+// content type is guessed from the file name when it is empty
+func (b *EmailBuilder) Attach(filename, contentType string, data []byte) *EmailBuilder {
+	if filename == "" {
+		b.errs = append(b.errs, fmt.Errorf("attachment needs a file name"))
 		return b
 	}
-	b.email.body = body
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(filename))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	if _, _, err := mime.ParseMediaType(contentType); err != nil {
+		b.errs = append(b.errs, fmt.Errorf("attachment %s has an invalid content type %q: %w", filename, contentType, err))
+		return b
+	}
+	b.email.attachments = append(b.email.attachments, attachment{filename, contentType, data})
 	return b
 }
 
-func sendMailEmailImpl(email *email) {
-	fmt.Printf("Email sent to `%s` by `%s` with subject `%s` and body as `%s`\n", email.to, email.from, email.subject, email.body)
+var reservedHeaders = map[string]bool{
+	"From": true, "To": true, "Cc": true, "Bcc": true, "Subject": true, "Date": true,
+	"Mime-Version": true, "Content-Type": true, "Content-Transfer-Encoding": true,
+}
+
+// everything that went wrong while building along with what is missing
+func (b *EmailBuilder) validate() error {
+	errs := append([]error(nil), b.errs...)
+	if !b.fromGiven {
+		errs = append(errs, fmt.Errorf("From is required"))
+	}
+	if len(b.email.recipients()) == 0 {
+		errs = append(errs, fmt.Errorf("at least one of To, Cc or Bcc is required"))
+	}
+	if b.email.body == "" && b.email.html == "" {
+		errs = append(errs, fmt.Errorf("Body or HTML is required"))
+	}
+	return errors.Join(errs...)
+}
+
+// the whole message as it is sent, RFC 5322 headers followed by the MIME body
+func (e *email) WriteTo(w io.Writer) (int64, error) {
+	buf := bytes.Buffer{}
+	date := e.date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+	header("From", e.from)
+	if len(e.to) > 0 {
+		header("To", strings.Join(e.to, ", "))
+	}
+	if len(e.cc) > 0 {
+		header("Cc", strings.Join(e.cc, ", "))
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", e.subject))
+	header("Date", date.Format(time.RFC1123Z))
+	for _, name := range slices.Sorted(maps.Keys(e.headers)) {
+		for _, v := range e.headers[name] {
+			header(name, mime.QEncoding.Encode("utf-8", v))
+		}
+	}
+	header("MIME-Version", "1.0")
+
+	if err := e.writeBody(&buf); err != nil {
+		return 0, err
+	}
+	return buf.WriteTo(w)
+}
+
+func (e *email) Bytes() ([]byte, error) {
+	buf := bytes.Buffer{}
+	_, err := e.WriteTo(&buf)
+	return buf.Bytes(), err
+}
+
+func textPart(contentType, text string) (textproto.MIMEHeader, []byte, error) {
+	buf := bytes.Buffer{}
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(text)); err != nil {
+		return nil, nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	return textproto.MIMEHeader{
+		"Content-Type":              {contentType + "; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	}, buf.Bytes(), nil
+}
+
+// base64 wrapped at 76 characters
+func base64Lines(data []byte) []byte {
+	enc := base64.StdEncoding.EncodeToString(data)
+	buf := bytes.Buffer{}
+	for len(enc) > 76 {
+		buf.WriteString(enc[:76] + "\r\n")
+		enc = enc[76:]
+	}
+	buf.WriteString(enc + "\r\n")
+	return buf.Bytes()
+}
+
+// text, html or both as multipart/alternative, wrapped in multipart/mixed when there are attachments
+func (e *email) content() (textproto.MIMEHeader, []byte, error) {
+	var alternatives [][2]string
+	if e.body != "" {
+		alternatives = append(alternatives, [2]string{"text/plain", e.body})
+	}
+	if e.html != "" {
+		alternatives = append(alternatives, [2]string{"text/html", e.html})
+	}
+
+	if len(alternatives) == 1 {
+		return textPart(alternatives[0][0], alternatives[0][1])
+	}
+
+	buf := bytes.Buffer{}
+	mw := multipart.NewWriter(&buf)
+	for _, alt := range alternatives {
+		h, body, err := textPart(alt[0], alt[1])
+		if err != nil {
+			return nil, nil, err
+		}
+		pw, err := mw.CreatePart(h)
+		if err != nil {
+			return nil, nil, err
+		}
+		pw.Write(body)
+	}
+	if err := mw.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	return textproto.MIMEHeader{
+		"Content-Type": {mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": mw.Boundary()})},
+	}, buf.Bytes(), nil
+}
+
+func (e *email) writeBody(buf *bytes.Buffer) error {
+	h, body, err := e.content()
+	if err != nil {
+		return err
+	}
+
+	writeHeader := func(h textproto.MIMEHeader) {
+		for _, name := range []string{"Content-Type", "Content-Transfer-Encoding"} {
+			if v := h.Get(name); v != "" {
+				fmt.Fprintf(buf, "%s: %s\r\n", name, v)
+			}
+		}
+		buf.WriteString("\r\n")
+	}
+
+	if len(e.attachments) == 0 {
+		writeHeader(h)
+		buf.Write(body)
+		return nil
+	}
+
+	parts := bytes.Buffer{}
+	mw := multipart.NewWriter(&parts)
+	pw, err := mw.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	pw.Write(body)
+
+	for _, a := range e.attachments {
+		mediaType, params, err := mime.ParseMediaType(a.contentType)
+		if err != nil {
+			return err
+		}
+		params["name"] = a.filename
+
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(mediaType, params)},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.filename})},
+		})
+		if err != nil {
+			return err
+		}
+		pw.Write(base64Lines(a.data))
+	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+
+	writeHeader(textproto.MIMEHeader{
+		"Content-Type": {mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": mw.Boundary()})},
+	})
+	buf.Write(parts.Bytes())
+	return nil
 }
 
 type build func(*EmailBuilder)
 
+// sends through DefaultTransport
 func SendMail(action build) error {
+	return SendMailWith(DefaultTransport, action)
+}
+
+func SendMailWith(transport Transport, action build) error {
 	emailBuilder := EmailBuilder{}
 	action(&emailBuilder)
-	if emailBuilder.err != nil {
-		return emailBuilder.err
+	if err := emailBuilder.validate(); err != nil {
+		return err
 	}
-	sendMailEmailImpl(&emailBuilder.email)
-	return nil
+
+	msg, err := emailBuilder.email.Bytes()
+	if err != nil {
+		return err
+	}
+	from, to := emailBuilder.email.envelope()
+	return transport.Send(from, to, msg)
 }
 
 func TestBuilderParams() {
@@ -79,7 +377,7 @@ func TestBuilderParams() {
 			Body("Hello, how are you?")
 	})
 	if err != nil {
-		fmt.Printf("Error sending email: %v", err.Error())
+		fmt.Printf("Error sending email: %v\n", err.Error())
 	}
 	err = SendMail(func(eb *EmailBuilder) {
 		eb.
@@ -89,6 +387,59 @@ func TestBuilderParams() {
 			Body("Hello, how are you?")
 	})
 	if err != nil {
-		fmt.Printf("Error sending email: %v", err.Error())
+		fmt.Printf("Error sending email: %v\n", err.Error())
+	}
+
+	//	every problem is reported, not just the first one
+	err = SendMail(func(eb *EmailBuilder) {
+		eb.
+			From("abcgmail.com").
+			To("def", "ghi@yahoo.com").
+			Header("Bcc", "sneaky@example.com").
+			Subject("Second\r\nBcc: sneaky@example.com")
+	})
+	if err != nil {
+		fmt.Printf("\nError sending email:\n%v\n", err.Error())
+	}
+
+	eb := EmailBuilder{}
+	eb.
+		From("Abc <abc@gmail.com>").
+		To("def@yahoo.com").
+		Cc("ghi@yahoo.com").
+		Bcc("boss@gmail.com").
+		Subject("Report for Zoë").
+		Date(time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)).
+		Header("X-Priority", "1").
+		Body("The report is attached.").
+		HTML("<p>The report is <b>attached</b>.</p>").
+		Attach("report.csv", "", []byte("name,score\nabc,10\n"))
+	if err := eb.validate(); err != nil {
+		fmt.Println(err)
+		return
+	}
+	msg, err := eb.email.Bytes()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(strings.ReplaceAll(string(msg), "\r\n", "\n"))
+
+	//	the standard library should be able to read it back
+	parsed, err := mail.ReadMessage(bytes.NewReader(msg))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	mediaType, params, _ := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	mr := multipart.NewReader(parsed.Body, params["boundary"])
+	parts := 0
+	for {
+		_, err := mr.NextPart()
+		if err != nil {
+			break
+		}
+		parts++
 	}
+	fmt.Println(mediaType, "with", parts, "parts")
 }
@@ -0,0 +1,125 @@
+// Code generated by builder-gen; DO NOT EDIT.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+type ContactBuilder struct {
+	value Contact
+	errs  []error
+	given map[string]bool //	set even when the value was invalid, so it is not reported twice
+}
+
+func NewContactBuilder() *ContactBuilder {
+	return &ContactBuilder{given: map[string]bool{}}
+}
+
+type ContactAddressBuilder struct {
+	*ContactBuilder
+}
+
+func (b *ContactBuilder) Address() *ContactAddressBuilder {
+	return &ContactAddressBuilder{ContactBuilder: b}
+}
+
+type ContactJobBuilder struct {
+	*ContactBuilder
+}
+
+func (b *ContactBuilder) Job() *ContactJobBuilder {
+	return &ContactJobBuilder{ContactBuilder: b}
+}
+
+func (b *ContactBuilder) setName(v string) {
+	b.given["Name"] = true
+	if strings.TrimSpace(v) == "" {
+		b.errs = append(b.errs, fmt.Errorf("Name should not be empty, got %q", v))
+		return
+	}
+	b.value.Name = v
+}
+func (b *ContactBuilder) Called(v string) *ContactBuilder {
+	b.setName(v)
+	return b
+}
+
+func (b *ContactBuilder) setEmail(v string) {
+	b.given["Email"] = true
+	if _, err := mail.ParseAddress(v); err != nil {
+		b.errs = append(b.errs, fmt.Errorf("Email should be an email address, got %q", v))
+		return
+	}
+	b.value.Email = v
+}
+func (b *ContactBuilder) Email(v string) *ContactBuilder {
+	b.setEmail(v)
+	return b
+}
+
+func (b *ContactBuilder) setAge(v int) {
+	b.given["Age"] = true
+	if v <= 0 {
+		b.errs = append(b.errs, fmt.Errorf("Age should be positive, got %v", v))
+		return
+	}
+	b.value.Age = v
+}
+func (b *ContactBuilder) Age(v int) *ContactBuilder {
+	b.setAge(v)
+	return b
+}
+
+func (b *ContactBuilder) setStreet(v string) {
+	b.given["Street"] = true
+	b.value.Street = v
+}
+func (b *ContactAddressBuilder) At(v string) *ContactAddressBuilder {
+	b.ContactBuilder.setStreet(v)
+	return b
+}
+
+func (b *ContactBuilder) setCity(v string) {
+	b.given["City"] = true
+	b.value.City = v
+}
+func (b *ContactAddressBuilder) In(v string) *ContactAddressBuilder {
+	b.ContactBuilder.setCity(v)
+	return b
+}
+
+func (b *ContactBuilder) setPostcode(v string) {
+	b.given["Postcode"] = true
+	b.value.Postcode = v
+}
+func (b *ContactAddressBuilder) WithPostCode(v string) *ContactAddressBuilder {
+	b.ContactBuilder.setPostcode(v)
+	return b
+}
+
+func (b *ContactBuilder) setCompany(v string) {
+	b.given["Company"] = true
+	b.value.Company = v
+}
+func (b *ContactJobBuilder) WorksAt(v string) *ContactJobBuilder {
+	b.ContactBuilder.setCompany(v)
+	return b
+}
+
+// checks the required fields, every problem is reported together
+func (b *ContactBuilder) Build() (Contact, error) {
+	errs := append([]error(nil), b.errs...)
+	for _, f := range []string{"Name", "Email"} {
+		if !b.given[f] {
+			errs = append(errs, fmt.Errorf("%s is required", f))
+		}
+	}
+	if len(errs) > 0 {
+		return Contact{}, errors.Join(errs...)
+	}
+	return b.value, nil
+}
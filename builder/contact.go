@@ -0,0 +1,32 @@
+package main
+
+import "fmt"
+
+//go:generate go run ./builder-gen -type Contact -output contact-builder.go
+
+type Contact struct {
+	Name     string `builder:"required,validate=nonempty,method=Called"`
+	Email    string `builder:"required,validate=email"`
+	Age      int    `builder:"validate=positive"`
+	Street   string `builder:"facet=address,method=At"`
+	City     string `builder:"facet=address,method=In"`
+	Postcode string `builder:"facet=address,method=WithPostCode"`
+	Company  string `builder:"facet=job,method=WorksAt"`
+}
+
+func TestGeneratedBuilder() {
+	c, err := NewContactBuilder().
+		Called("Shreyash").
+		Email("shreyash@example.com").
+		Address().At("212 Baker Street").In("London").WithPostCode("SW12BC").
+		Job().WorksAt("Zapcom").
+		Build()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%+v\n", c)
+
+	_, err = NewContactBuilder().Called(" ").Email("nope").Age(-1).Build()
+	fmt.Println(err)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// facet written the way another package would, only using what PersonBuilder exports
+type PersonIncomeBuilder struct {
+	PersonBuilder
+	amount   int
+	currency string
+}
+
+func Earns(b *PersonBuilder) *PersonIncomeBuilder {
+	ib := &PersonIncomeBuilder{PersonBuilder: *b, currency: "USD"}
+	b.RegisterFacet("income", func(p Person) error {
+		amount, currency, ok := strings.Cut(p.AnnualIncome, " ")
+		if !ok || len(currency) != 3 {
+			return fmt.Errorf("annual income %q should be an amount and a currency", p.AnnualIncome)
+		}
+		n, err := strconv.Atoi(amount)
+		if err != nil {
+			return fmt.Errorf("annual income amount %q is not a whole number", amount)
+		}
+		if n < 0 {
+			return errors.New("annual income should not be negative")
+		}
+		return nil
+	})
+	return ib
+}
+
+func (b *PersonIncomeBuilder) update() {
+	b.Update(func(p *Person) {
+		p.AnnualIncome = fmt.Sprintf("%d %s", b.amount, b.currency)
+	})
+}
+
+func (b *PersonIncomeBuilder) PerYear(amount int) *PersonIncomeBuilder {
+	b.amount = amount
+	b.update()
+	return b
+}
+func (b *PersonIncomeBuilder) In(currency string) *PersonIncomeBuilder {
+	b.currency = strings.ToUpper(currency)
+	b.update()
+	return b
+}
@@ -1,6 +1,11 @@
 package main
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
 
 type Person struct {
 	StreetAddress string
@@ -16,8 +21,15 @@ func (p *Person) Introduce() {
 	fmt.Printf("I live at %s, %s, %s and I work at %s as a %s and I earn %s", p.StreetAddress, p.Postcode, p.City, p.Company, p.Position, p.AnnualIncome)
 }
 
+// shared by every facet builder, they all get a copy of PersonBuilder so this has to be a pointer
+type facetState struct {
+	rules map[string]func(Person) error
+	order []string
+}
+
 type PersonBuilder struct {
 	person *Person
+	facets *facetState
 }
 
 type PersonAddressBuilder struct {
@@ -36,8 +48,49 @@ func (b *PersonBuilder) Works() *PersonJobBuilder {
 	return &PersonJobBuilder{PersonBuilder: *b}
 }
 
-func (b *PersonBuilder) Build() *Person {
-	return b.person
+// the checks a facet needs to pass before the person can be built, registering a facet again replaces them
+// facet builders from other packages register themselves through this
+func (b *PersonBuilder) RegisterFacet(name string, validate func(Person) error) {
+	if _, ok := b.facets.rules[name]; !ok {
+		b.facets.order = append(b.facets.order, name)
+	}
+	b.facets.rules[name] = validate
+}
+
+// lets facet builders from other packages change the person
+func (b *PersonBuilder) Update(action func(*Person)) {
+	action(b.person)
+}
+
+// checks every facet and returns a copy, so building again later does not change it
+func (b *PersonBuilder) Build() (*Person, error) {
+	var errs []error
+	for _, name := range b.facets.order {
+		if err := b.facets.rules[name](*b.person); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	p := *b.person
+	return &p, nil
+}
+
+// error listing the fields which are empty
+func requireFields(fields map[string]string) error {
+	var missing []string
+	for name, value := range fields {
+		if strings.TrimSpace(value) == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	slices.Sort(missing)
+	return fmt.Errorf("%s required", strings.Join(missing, ", "))
 }
 
 // AddressBuilder methods
@@ -69,7 +122,18 @@ func (b *PersonJobBuilder) Earning(annualIncome string) *PersonJobBuilder {
 }
 
 func NewPersonBuilder() *PersonBuilder {
-	return &PersonBuilder{person: &Person{}}
+	b := &PersonBuilder{
+		person: &Person{},
+		facets: &facetState{rules: map[string]func(Person) error{}},
+	}
+
+	b.RegisterFacet("address", func(p Person) error {
+		return requireFields(map[string]string{"street address": p.StreetAddress, "city": p.City})
+	})
+	b.RegisterFacet("job", func(p Person) error {
+		return requireFields(map[string]string{"company": p.Company, "position": p.Position})
+	})
+	return b
 }
 
 func TestBuilderFacet() {
@@ -85,7 +149,36 @@ func TestBuilderFacet() {
 		AsA("Software Engineer").
 		Earning("Nothing")
 
-	p := pb.Build()
+	p, err := pb.Build()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 	p.Introduce()
-
+	fmt.Println()
+
+	//	the built person is a copy, the builder can carry on without changing it
+	pb.Works().At("Baker Street Irregulars")
+	p2, _ := pb.Build()
+	fmt.Println(p.Company, "|", p2.Company)
+
+	//	every facet with missing fields is reported
+	_, err = NewPersonBuilder().Lives().In("London").Build()
+	fmt.Println(err)
+
+	//	facet added without touching PersonBuilder
+	ib := NewPersonBuilder()
+	ib.Lives().At("221B Baker Street").In("London").
+		Works().At("Zapcom").AsA("Software Engineer")
+	_, err = Earns(ib).PerYear(-5).In("GBP").Build()
+	fmt.Println(err)
+	_, err = Earns(ib).Works().Earning("abc USD").Build()
+	fmt.Println(err)
+	p, err = Earns(ib).PerYear(50000).In("GBP").Build()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	p.Introduce()
+	fmt.Println()
 }
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+)
+
+// just enough of an smtp server to try the SMTPTransport against, it offers STARTTLS and AUTH PLAIN
+type fakeSMTPServer struct {
+	ln        net.Listener
+	user      string
+	pass      string
+	serverTLS *tls.Config
+	clientTLS *tls.Config
+
+	mu       sync.Mutex
+	received []SentMail
+	usedTLS  bool
+	wg       sync.WaitGroup
+}
+
+func startFakeSMTPServer(user, pass string) (*fakeSMTPServer, error) {
+	serverTLS, clientTLS, err := selfSignedTLS()
+	if err != nil {
+		return nil, err
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &fakeSMTPServer{ln: ln, user: user, pass: pass, serverTLS: serverTLS, clientTLS: clientTLS}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.serve(conn)
+			}()
+		}
+	}()
+	return s, nil
+}
+
+// certificate for 127.0.0.1 and a client config which trusts it
+func selfSignedTLS() (*tls.Config, *tls.Config, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "fake smtp"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}},
+		&tls.Config{RootCAs: pool},
+		nil
+}
+
+func (s *fakeSMTPServer) Addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *fakeSMTPServer) ClientTLSConfig() *tls.Config {
+	return s.clientTLS.Clone()
+}
+
+func (s *fakeSMTPServer) Received() []SentMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SentMail(nil), s.received...)
+}
+
+func (s *fakeSMTPServer) UsedTLS() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.usedTLS
+}
+
+func (s *fakeSMTPServer) Close() error {
+	err := s.ln.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer func() { conn.Close() }()
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 fake ESMTP")
+
+	secure, authed := false, false
+	mail := SentMail{}
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			if !secure {
+				tp.PrintfLine("250-fake")
+				tp.PrintfLine("250-STARTTLS")
+			} else {
+				tp.PrintfLine("250-fake")
+			}
+			tp.PrintfLine("250 AUTH PLAIN")
+
+		case "STARTTLS":
+			tp.PrintfLine("220 go ahead")
+			tlsConn := tls.Server(conn, s.serverTLS)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, tp, secure = tlsConn, textproto.NewConn(tlsConn), true
+			s.mu.Lock()
+			s.usedTLS = true
+			s.mu.Unlock()
+
+		case "AUTH":
+			mech, resp, _ := strings.Cut(arg, " ")
+			decoded, err := base64.StdEncoding.DecodeString(resp)
+			if strings.ToUpper(mech) != "PLAIN" || err != nil {
+				tp.PrintfLine("504 only AUTH PLAIN with an initial response")
+				continue
+			}
+			if !bytes.Equal(decoded, []byte("\x00"+s.user+"\x00"+s.pass)) {
+				tp.PrintfLine("535 authentication failed")
+				continue
+			}
+			authed = true
+			tp.PrintfLine("235 authenticated")
+
+		case "MAIL":
+			if !authed {
+				tp.PrintfLine("530 authentication required")
+				continue
+			}
+			mail = SentMail{From: strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")}
+			tp.PrintfLine("250 ok")
+
+		case "RCPT":
+			mail.To = append(mail.To, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			tp.PrintfLine("250 ok")
+
+		case "DATA":
+			tp.PrintfLine("354 end with .")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			mail.Data = data
+			s.mu.Lock()
+			s.received = append(s.received, mail)
+			s.mu.Unlock()
+			tp.PrintfLine("250 queued")
+
+		case "RSET", "NOOP":
+			tp.PrintfLine("250 ok")
+
+		case "QUIT":
+			tp.PrintfLine("221 bye")
+			return
+
+		default:
+			tp.PrintfLine("502 %s is not implemented", verb)
+		}
+	}
+}
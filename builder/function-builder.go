@@ -57,4 +57,7 @@ func TestFunctionalBuilder() {
 	Introduce(func(pa *PersonABuilder) {
 		pa.Called("Shreyash").Is("Developer")
 	})
+	fmt.Println()
+
+	TestGenericBuilder()
 }
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+type rule[T any] struct {
+	name  string
+	check func(T) error
+}
+
+// functional builder for any struct, same idea as PersonABuilder but written once
+type Builder[T any] struct {
+	actions []func(*T)
+	rules   []rule[T]
+}
+
+func NewBuilder[T any]() *Builder[T] {
+	return &Builder[T]{}
+}
+
+func (b *Builder[T]) With(actions ...func(*T)) *Builder[T] {
+	b.actions = append(b.actions, actions...)
+	return b
+}
+
+// checked on the finished object when Build is called
+func (b *Builder[T]) Rule(name string, check func(T) error) *Builder[T] {
+	b.rules = append(b.rules, rule[T]{name, check})
+	return b
+}
+
+// runs every action and then every rule, all the rules that fail are reported
+func (b *Builder[T]) Build() (T, error) {
+	var t T
+	for _, a := range b.actions {
+		a(&t)
+	}
+
+	var errs []error
+	for _, r := range b.rules {
+		if err := r.check(t); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.name, err))
+		}
+	}
+	if len(errs) > 0 {
+		var zero T
+		return zero, errors.Join(errs...)
+	}
+	return t, nil
+}
+
+// copy of the builder which can be changed without touching the original
+// a partly configured builder can be used as a template this way
+func (b *Builder[T]) Clone() *Builder[T] {
+	return &Builder[T]{
+		actions: slices.Clone(b.actions),
+		rules:   slices.Clone(b.rules),
+	}
+}
+
+func TestGenericBuilder() {
+	called := func(name string) func(*PersonA) {
+		return func(p *PersonA) { p.name = name }
+	}
+	is := func(position string) func(*PersonA) {
+		return func(p *PersonA) { p.postion = position }
+	}
+
+	//	every developer starts from this
+	developer := NewBuilder[PersonA]().
+		With(is("Developer")).
+		Rule("name is required", func(p PersonA) error {
+			if p.name == "" {
+				return errors.New("name is empty")
+			}
+			return nil
+		}).
+		Rule("position is required", func(p PersonA) error {
+			if p.postion == "" {
+				return errors.New("position is empty")
+			}
+			return nil
+		})
+
+	for _, name := range []string{"Shreyash", "Dmitri"} {
+		p, err := developer.Clone().With(called(name)).Build()
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		introducePerson(&p)
+		fmt.Println()
+	}
+
+	//	the template itself was never given a name
+	if _, err := developer.Build(); err != nil {
+		fmt.Println(err)
+	}
+	_, err := developer.Clone().With(is("")).Build()
+	fmt.Println(err)
+}
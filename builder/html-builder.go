@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"html"
+	"slices"
 	"strings"
 )
 
@@ -9,73 +11,177 @@ const (
 	IndentSize = 2
 )
 
+type HtmlAttr struct {
+	Name, Value string
+}
+
+// an element with an empty name is a text node
 type HtmlElement struct {
 	name     string
 	text     string
-	elements []HtmlElement
+	raw      bool //	text is written as it is, without escaping
+	attrs    []HtmlAttr
+	elements []*HtmlElement
 }
 
 func (e *HtmlElement) String() string {
-	return e.string(0)
+	return e.Render(DefaultRenderOptions)
+}
+
+func (e *HtmlElement) isText() bool {
+	return e.name == ""
+}
+
+func (e *HtmlElement) escapedText() string {
+	if e.raw {
+		return e.text
+	}
+	return html.EscapeString(e.text)
 }
 
-func (e *HtmlElement) string(indent int) string {
+func (e *HtmlElement) openTag(selfClose bool) string {
 	sb := strings.Builder{}
-	i := strings.Repeat(" ", IndentSize*indent)
-	sb.WriteString(fmt.Sprintf("%s<%s>\n", i, e.name))
+	sb.WriteString("<" + html.EscapeString(e.name))
+	for _, a := range e.attrs {
+		sb.WriteString(fmt.Sprintf(" %s=\"%s\"", html.EscapeString(a.Name), html.EscapeString(a.Value)))
+	}
+	if selfClose {
+		sb.WriteString(" />")
+	} else {
+		sb.WriteString(">")
+	}
+	return sb.String()
+}
+
+func (e *HtmlElement) closeTag() string {
+	return "</" + html.EscapeString(e.name) + ">"
+}
 
-	if len(e.text) > 0 {
-		sb.WriteString(strings.Repeat(" ", IndentSize*(indent+1)))
-		sb.WriteString(e.text)
-		sb.WriteString("\n")
+func (e *HtmlElement) Attr(name string) (string, bool) {
+	for _, a := range e.attrs {
+		if a.Name == name {
+			return a.Value, true
+		}
 	}
-	for _, el := range e.elements {
-		sb.WriteString(el.string(indent + 1))
+	return "", false
+}
+
+// replaces the value if the attribute is already there
+func (e *HtmlElement) SetAttr(name, value string) {
+	for i := range e.attrs {
+		if e.attrs[i].Name == name {
+			e.attrs[i].Value = value
+			return
+		}
 	}
+	e.attrs = append(e.attrs, HtmlAttr{name, value})
+}
 
-	sb.WriteString(fmt.Sprintf("%s</%s>\n", i, e.name))
+func (e *HtmlElement) Classes() []string {
+	class, _ := e.Attr("class")
+	return strings.Fields(class)
+}
 
-	return sb.String()
+func (e *HtmlElement) AddClass(classes ...string) {
+	current := e.Classes()
+	for _, c := range classes {
+		if !slices.Contains(current, c) {
+			current = append(current, c)
+		}
+	}
+	e.SetAttr("class", strings.Join(current, " "))
 }
 
 type HtmlBuilder struct {
 	rootName string
 	root     HtmlElement
+	current  *HtmlElement   //	the element children are added to
+	parents  []*HtmlElement //	the way back up to the root
 }
 
 func (b *HtmlBuilder) String() string {
 	return b.root.String()
 }
 
+func (b *HtmlBuilder) cur() *HtmlElement {
+	if b.current == nil {
+		b.current = &b.root
+	}
+	return b.current
+}
+
 func (b *HtmlBuilder) AddChild(childName, childText string) {
 	e := HtmlElement{
 		name:     childName,
 		text:     childText,
-		elements: []HtmlElement{},
+		elements: []*HtmlElement{},
 	}
-	b.root.elements = append(b.root.elements, e)
+	b.cur().elements = append(b.cur().elements, &e)
 }
 
 func (b *HtmlBuilder) AddChildFluent(childName, childText string) *HtmlBuilder {
-	e := HtmlElement{
+	b.AddChild(childName, childText)
+
+	return b
+}
+
+// adds a child and moves into it, everything after this is added to the child until Up is called
+func (b *HtmlBuilder) Child(childName string) *HtmlBuilder {
+	e := &HtmlElement{
 		name:     childName,
-		text:     childText,
-		elements: []HtmlElement{},
+		elements: []*HtmlElement{},
+	}
+	b.cur().elements = append(b.cur().elements, e)
+	b.parents = append(b.parents, b.cur())
+	b.current = e
+
+	return b
+}
+
+// moves back to the parent, does nothing at the root
+func (b *HtmlBuilder) Up() *HtmlBuilder {
+	if len(b.parents) == 0 {
+		return b
 	}
-	b.root.elements = append(b.root.elements, e)
+	b.current = b.parents[len(b.parents)-1]
+	b.parents = b.parents[:len(b.parents)-1]
+
+	return b
+}
 
+func (b *HtmlBuilder) Attr(name, value string) *HtmlBuilder {
+	b.cur().SetAttr(name, value)
+	return b
+}
+
+func (b *HtmlBuilder) Class(classes ...string) *HtmlBuilder {
+	b.cur().AddClass(classes...)
+	return b
+}
+
+// text is escaped when it is written out
+func (b *HtmlBuilder) Text(text string) *HtmlBuilder {
+	b.cur().elements = append(b.cur().elements, &HtmlElement{text: text})
+	return b
+}
+
+// html which is written out as it is
+func (b *HtmlBuilder) Raw(html string) *HtmlBuilder {
+	b.cur().elements = append(b.cur().elements, &HtmlElement{text: html, raw: true})
 	return b
 }
 
 func NewHtmlBuilder(rootName string) *HtmlBuilder {
-	return &HtmlBuilder{
+	b := &HtmlBuilder{
 		rootName: rootName,
 		root: HtmlElement{
 			name:     rootName,
 			text:     "",
-			elements: []HtmlElement{},
+			elements: []*HtmlElement{},
 		},
 	}
+	b.current = &b.root
+	return b
 }
 
 func TestStringBuilder() {
@@ -111,4 +217,18 @@ func TestStringBuilder() {
 
 	fmt.Println(fb.String())
 
+	//	Nested one
+	nb := NewHtmlBuilder("div")
+	nb.Class("card").Attr("id", "main").
+		Child("h1").Text("Fish & Chips").Up().
+		Child("ul").Class("menu", "compact").
+		Child("li").Text("<cod>").Up().
+		Child("li").Child("a").Attr("href", "/order?fish=haddock&size=\"large\"").Text("haddock").Up().Up().
+		Up().
+		Raw("<!-- served hot -->")
+
+	fmt.Println(nb.String())
+
+	TestHtmlParser()
+	TestHtmlQuery()
 }
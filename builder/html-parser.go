@@ -0,0 +1,328 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+)
+
+// elements which never have children or a closing tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// elements whose content is not html
+var rawTextElements = map[string]bool{
+	"script": true,
+	"style":  true,
+}
+
+type HtmlParseError struct {
+	Line, Column int
+	Msg          string
+}
+
+func (e *HtmlParseError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
+type htmlParser struct {
+	src string
+	pos int
+}
+
+func (p *htmlParser) errorAt(pos int, format string, args ...any) error {
+	before := p.src[:pos]
+	line := strings.Count(before, "\n") + 1
+	column := pos - strings.LastIndex(before, "\n")
+	return &HtmlParseError{Line: line, Column: column, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *htmlParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *htmlParser) skipSpace() {
+	for !p.eof() && strings.IndexByte(" \t\r\n\f", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func isNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == ':' || c == '.'
+}
+
+func (p *htmlParser) name() string {
+	start := p.pos
+	for !p.eof() && isNameChar(p.src[p.pos]) {
+		p.pos++
+	}
+	return strings.ToLower(p.src[start:p.pos])
+}
+
+// parses nodes until the closing tag of parent, or the end of the input for the top level
+func (p *htmlParser) nodes(parent *HtmlElement, openedAt int) ([]*HtmlElement, error) {
+	var nodes []*HtmlElement
+	for {
+		if p.eof() {
+			if parent != nil {
+				return nil, p.errorAt(openedAt, "<%s> is never closed", parent.name)
+			}
+			return nodes, nil
+		}
+
+		start := p.pos
+		rest := p.src[p.pos:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			end := strings.Index(rest, "-->")
+			if end < 0 {
+				return nil, p.errorAt(start, "comment is never closed")
+			}
+			nodes = append(nodes, &HtmlElement{text: rest[:end+3], raw: true})
+			p.pos += end + 3
+
+		case strings.HasPrefix(rest, "<!"):
+			//	doctype and friends are skipped
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				return nil, p.errorAt(start, "<! is never closed")
+			}
+			p.pos += end + 1
+
+		case strings.HasPrefix(rest, "</"):
+			p.pos += 2
+			name := p.name()
+			p.skipSpace()
+			if p.eof() || p.src[p.pos] != '>' {
+				return nil, p.errorAt(p.pos, "expected > to end </%s", name)
+			}
+			p.pos++
+
+			switch {
+			case voidElements[name]:
+				//	</br> and the like are tolerated and ignored
+			case parent != nil && name == parent.name:
+				return nodes, nil
+			case parent != nil:
+				return nil, p.errorAt(start, "unexpected </%s>, <%s> is still open", name, parent.name)
+			default:
+				return nil, p.errorAt(start, "unexpected </%s>, nothing is open", name)
+			}
+
+		case len(rest) > 1 && rest[0] == '<' && isNameChar(rest[1]):
+			el, err := p.element()
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, el)
+
+		default:
+			//	a < that does not start a tag is just text
+			end := strings.IndexByte(rest[1:], '<')
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end++
+			}
+			p.pos += end
+
+			//	text keeps its spaces, only the indentation between tags is dropped,
+			//	a single space between two tags on one line still separates them
+			text := html.UnescapeString(rest[:end])
+			if strings.TrimSpace(text) != "" || !strings.ContainsAny(text, "\r\n") {
+				nodes = append(nodes, &HtmlElement{text: text})
+			}
+		}
+	}
+}
+
+func (p *htmlParser) element() (*HtmlElement, error) {
+	start := p.pos
+	p.pos++
+	el := &HtmlElement{name: p.name(), elements: []*HtmlElement{}}
+
+	for {
+		p.skipSpace()
+		if p.eof() {
+			return nil, p.errorAt(start, "<%s is never closed with >", el.name)
+		}
+
+		switch c := p.src[p.pos]; {
+		case c == '>':
+			p.pos++
+			return p.content(el, start)
+
+		case strings.HasPrefix(p.src[p.pos:], "/>"):
+			p.pos += 2
+			return el, nil
+
+		case isNameChar(c):
+			name := p.name()
+			value := ""
+			p.skipSpace()
+			if !p.eof() && p.src[p.pos] == '=' {
+				p.pos++
+				p.skipSpace()
+				v, err := p.attrValue()
+				if err != nil {
+					return nil, err
+				}
+				value = v
+			}
+			el.SetAttr(name, value)
+
+		default:
+			return nil, p.errorAt(p.pos, "unexpected %q in <%s>", c, el.name)
+		}
+	}
+}
+
+func (p *htmlParser) attrValue() (string, error) {
+	if p.eof() {
+		return "", p.errorAt(p.pos, "expected an attribute value")
+	}
+
+	start := p.pos
+	if q := p.src[p.pos]; q == '"' || q == '\'' {
+		end := strings.IndexByte(p.src[p.pos+1:], q)
+		if end < 0 {
+			return "", p.errorAt(start, "attribute value is never closed with %c", q)
+		}
+		p.pos += end + 2
+		return html.UnescapeString(p.src[start+1 : p.pos-1]), nil
+	}
+
+	//	<input type=checkbox/> is self closing, the / is not part of the value
+	for !p.eof() && strings.IndexByte(" \t\r\n\f>", p.src[p.pos]) < 0 &&
+		!strings.HasPrefix(p.src[p.pos:], "/>") {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorAt(start, "expected an attribute value")
+	}
+	return html.UnescapeString(p.src[start:p.pos]), nil
+}
+
+// index of the end tag in s, the name is matched ignoring case without lowercasing s,
+// which could change the length of s and move the index
+func indexEndTag(s, name string) int {
+	for i := 0; ; {
+		j := strings.Index(s[i:], "</")
+		if j < 0 {
+			return -1
+		}
+		j += i + 2
+		if end := j + len(name); end <= len(s) && strings.EqualFold(s[j:end], name) &&
+			(end == len(s) || !isNameChar(s[end])) {
+			return j - 2
+		}
+		i = j
+	}
+}
+
+// everything between the start tag and the end tag
+func (p *htmlParser) content(el *HtmlElement, openedAt int) (*HtmlElement, error) {
+	if voidElements[el.name] {
+		return el, nil
+	}
+
+	if rawTextElements[el.name] {
+		end := indexEndTag(p.src[p.pos:], el.name)
+		if end < 0 {
+			return nil, p.errorAt(openedAt, "<%s> is never closed", el.name)
+		}
+		if text := p.src[p.pos : p.pos+end]; strings.TrimSpace(text) != "" {
+			el.elements = append(el.elements, &HtmlElement{text: text, raw: true})
+		}
+		p.pos += end
+		rest, err := p.nodes(el, openedAt)
+		if err != nil {
+			return nil, err
+		}
+		el.elements = append(el.elements, rest...)
+		return el, nil
+	}
+
+	children, err := p.nodes(el, openedAt)
+	if err != nil {
+		return nil, err
+	}
+	el.elements = append(el.elements, children...)
+	return el, nil
+}
+
+// every top level node of the fragment, text included
+func ParseHtmlFragment(src string) ([]*HtmlElement, error) {
+	p := htmlParser{src: src}
+	return p.nodes(nil, 0)
+}
+
+// the fragment has to have a single root element
+func ParseHtml(src string) (*HtmlElement, error) {
+	nodes, err := ParseHtmlFragment(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) != 1 || nodes[0].isText() {
+		return nil, fmt.Errorf("expected a single root element, found %d nodes", len(nodes))
+	}
+	return nodes[0], nil
+}
+
+// builder which carries on from an existing tree, children are added to the root.
+// the root is copied so appending on either side cant overwrite the others children
+func NewHtmlBuilderFrom(root *HtmlElement) *HtmlBuilder {
+	b := &HtmlBuilder{
+		rootName: root.name,
+		root:     *root,
+	}
+	b.root.elements = slices.Clone(root.elements)
+	b.root.attrs = slices.Clone(root.attrs)
+	b.current = &b.root
+	return b
+}
+
+func TestHtmlParser() {
+	template := `<!DOCTYPE html>
+<div class="card" id="main">
+  <h1>Fish &amp; Chips</h1>
+  <img src="cod.png" alt='a "fresh" cod'>
+  <input type=checkbox checked/>
+  <ul class="menu">
+    <li>cod</li>
+  </ul>
+  <script>if (a < b) { go() }</script>
+</div>`
+
+	root, err := ParseHtml(template)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	//	modify it with the builder and write it back out
+	b := NewHtmlBuilderFrom(root)
+	b.Child("p").Class("footer").Text("open < 9pm").Up()
+	fmt.Println(b.String())
+
+	//	what we write out can be read back in
+	again, err := ParseHtml(b.String())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("round trip is the same:", again.String() == b.String())
+
+	for _, bad := range []string{
+		"<ul>\n  <li>one</li>\n  <li>two\n</ul>",
+		"<div class=\"open>\n</div>",
+		"<p>\n  text\n",
+		"<p>\n  text</p>\n</span>",
+	} {
+		_, err := ParseHtml(bad)
+		fmt.Println("error:", err)
+	}
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// tag, #id, .class and [attr] all on the same element
+type simpleSelector struct {
+	tag     string //	empty or * matches any tag
+	id      string
+	classes []string
+	attrs   []string
+}
+
+func (s *simpleSelector) matches(e *HtmlElement) bool {
+	if e.isText() {
+		return false
+	}
+	if s.tag != "" && s.tag != "*" && s.tag != e.name {
+		return false
+	}
+	if s.id != "" {
+		if id, _ := e.Attr("id"); id != s.id {
+			return false
+		}
+	}
+	classes := e.Classes()
+	for _, c := range s.classes {
+		if !slices.Contains(classes, c) {
+			return false
+		}
+	}
+	for _, a := range s.attrs {
+		if _, ok := e.Attr(a); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+type selectorPart struct {
+	simpleSelector
+	combinator byte //	' ' or '>', how this part relates to the one before it
+}
+
+type selector []selectorPart
+
+func isSelectorNameChar(c byte) bool {
+	return isNameChar(c) && c != ':' && c != '.'
+}
+
+func parseSelector(src string) (selector, error) {
+	var sel selector
+	pos := 0
+	combinator := byte(' ')
+
+	name := func() string {
+		start := pos
+		for pos < len(src) && isSelectorNameChar(src[pos]) {
+			pos++
+		}
+		return src[start:pos]
+	}
+
+	for pos < len(src) {
+		switch c := src[pos]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			pos++
+			continue
+		case c == '>':
+			if len(sel) == 0 || combinator == '>' {
+				return nil, fmt.Errorf("selector %q: > needs an element on both sides", src)
+			}
+			combinator = '>'
+			pos++
+			continue
+		}
+
+		part := selectorPart{combinator: combinator}
+		if src[pos] == '*' {
+			part.tag = "*"
+			pos++
+		} else {
+			part.tag = strings.ToLower(name())
+		}
+
+	compound:
+		for pos < len(src) {
+			switch c := src[pos]; c {
+			case '#', '.':
+				pos++
+				n := name()
+				if n == "" {
+					return nil, fmt.Errorf("selector %q: expected a name after %c at %d", src, c, pos)
+				}
+				if c == '#' {
+					part.id = n
+				} else {
+					part.classes = append(part.classes, n)
+				}
+			case '[':
+				pos++
+				n := strings.ToLower(name())
+				if n == "" || pos >= len(src) || src[pos] != ']' {
+					return nil, fmt.Errorf("selector %q: only [attribute] is supported, at %d", src, pos)
+				}
+				pos++
+				part.attrs = append(part.attrs, n)
+			case ' ', '\t', '\n', '>':
+				break compound
+			default:
+				return nil, fmt.Errorf("selector %q: unsupported %q at %d", src, c, pos)
+			}
+		}
+
+		sel = append(sel, part)
+		combinator = ' '
+	}
+
+	if len(sel) == 0 {
+		return nil, fmt.Errorf("selector %q is empty", src)
+	}
+	if combinator == '>' {
+		return nil, fmt.Errorf("selector %q: > needs an element on both sides", src)
+	}
+	return sel, nil
+}
+
+// matches the selector right to left, ancestors go from the root down to the parent of e
+func (sel selector) matches(i int, e *HtmlElement, ancestors []*HtmlElement) bool {
+	if !sel[i].matches(e) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+
+	if sel[i].combinator == '>' {
+		if len(ancestors) == 0 {
+			return false
+		}
+		last := len(ancestors) - 1
+		return sel.matches(i-1, ancestors[last], ancestors[:last])
+	}
+
+	for j := len(ancestors) - 1; j >= 0; j-- {
+		if sel.matches(i-1, ancestors[j], ancestors[:j]) {
+			return true
+		}
+	}
+	return false
+}
+
+// a matched element, changes made through it are made to the tree itself
+type HtmlHandle struct {
+	*HtmlElement
+	parent *HtmlElement
+}
+
+// takes the element out of the tree, does nothing for the element the query was run on
+func (h *HtmlHandle) Remove() {
+	if h.parent == nil {
+		return
+	}
+	h.parent.elements = slices.DeleteFunc(h.parent.elements, func(e *HtmlElement) bool {
+		return e == h.HtmlElement
+	})
+	h.parent = nil
+}
+
+func (e *HtmlElement) RemoveAttr(name string) {
+	e.attrs = slices.DeleteFunc(e.attrs, func(a HtmlAttr) bool {
+		return a.Name == name
+	})
+}
+
+func (e *HtmlElement) RemoveClass(classes ...string) {
+	current := slices.DeleteFunc(e.Classes(), func(c string) bool {
+		return slices.Contains(classes, c)
+	})
+	if len(current) == 0 {
+		e.RemoveAttr("class")
+		return
+	}
+	e.SetAttr("class", strings.Join(current, " "))
+}
+
+// replaces everything inside the element with escaped text
+func (e *HtmlElement) SetText(text string) {
+	e.text = ""
+	e.raw = false
+	e.elements = []*HtmlElement{{text: text}}
+}
+
+func (e *HtmlElement) AppendChild(child *HtmlElement) {
+	e.elements = append(e.elements, child)
+}
+
+// every element matching any of the comma separated selectors, in document order
+// the element the query is run on can match too, like the root of a document
+func (e *HtmlElement) Query(selectors string) ([]*HtmlHandle, error) {
+	var sels []selector
+	for _, src := range strings.Split(selectors, ",") {
+		sel, err := parseSelector(src)
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+	}
+
+	var found []*HtmlHandle
+	var walk func(el *HtmlElement, ancestors []*HtmlElement)
+	walk = func(el *HtmlElement, ancestors []*HtmlElement) {
+		for _, sel := range sels {
+			if sel.matches(len(sel)-1, el, ancestors) {
+				h := &HtmlHandle{HtmlElement: el}
+				if len(ancestors) > 0 {
+					h.parent = ancestors[len(ancestors)-1]
+				}
+				found = append(found, h)
+				break
+			}
+		}
+
+		ancestors = append(ancestors, el)
+		for _, c := range el.elements {
+			walk(c, ancestors[:len(ancestors):len(ancestors)])
+		}
+	}
+	walk(e, nil)
+
+	return found, nil
+}
+
+// the first match, nil if nothing matches
+func (e *HtmlElement) QueryFirst(selectors string) (*HtmlHandle, error) {
+	found, err := e.Query(selectors)
+	if err != nil || len(found) == 0 {
+		return nil, err
+	}
+	return found[0], nil
+}
+
+func TestHtmlQuery() {
+	page, err := ParseHtml(`<div id="app">
+  <nav class="menu"><a href="/">home</a><a href="/about" class="active">about</a></nav>
+  <ul class="menu items">
+    <li data-sold-out>cod</li>
+    <li>haddock <span class="price">5</span></li>
+    <li><ul><li>nested</li></ul></li>
+  </ul>
+</div>`)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	show := func(selector string) []*HtmlHandle {
+		found, err := page.Query(selector)
+		if err != nil {
+			fmt.Println(err)
+			return nil
+		}
+		names := []string{}
+		for _, h := range found {
+			names = append(names, h.Render(RenderOptions{Compact: true}))
+		}
+		fmt.Printf("%-22s %d %v\n", selector, len(found), names)
+		return found
+	}
+
+	show("a")
+	show("#app > .menu")
+	show("ul.items > li")
+	show("ul.items li")
+	show("li[data-sold-out], .price")
+	show("nav a.active")
+
+	//	mutations through the handles change the page
+	for _, h := range show("li[data-sold-out]") {
+		h.Remove()
+	}
+	if h, _ := page.QueryFirst(".price"); h != nil {
+		h.SetText("6")
+		h.AddClass("changed")
+	}
+	if h, _ := page.QueryFirst("a.active"); h != nil {
+		h.RemoveClass("active")
+		h.SetAttr("aria-current", "page")
+	}
+	fmt.Println(page.Render(RenderOptions{Indent: 2, InlineText: true}))
+
+	_, err = page.Query("li:first-child")
+	fmt.Println("error:", err)
+}
@@ -0,0 +1,77 @@
+package main
+
+import "strings"
+
+type RenderOptions struct {
+	Compact    bool //	no whitespace at all between tags, Indent is ignored
+	Indent     int  //	spaces for every level of nesting
+	InlineText bool //	elements holding only text are written on a single line
+	XHTML      bool //	void elements are closed as <br />
+}
+
+// the way String has always written elements
+var DefaultRenderOptions = RenderOptions{Indent: IndentSize}
+
+func (e *HtmlElement) Render(opts RenderOptions) string {
+	sb := strings.Builder{}
+	e.render(&sb, opts, 0)
+	return sb.String()
+}
+
+// the text field is written out like a text node in front of the children
+func (e *HtmlElement) children() []*HtmlElement {
+	if len(e.text) == 0 || e.isText() {
+		return e.elements
+	}
+	return append([]*HtmlElement{{text: e.text, raw: e.raw}}, e.elements...)
+}
+
+func (e *HtmlElement) onlyText() bool {
+	for _, c := range e.children() {
+		if !c.isText() {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *HtmlElement) render(sb *strings.Builder, opts RenderOptions, depth int) {
+	indent, newline := "", ""
+	if !opts.Compact {
+		indent, newline = strings.Repeat(" ", opts.Indent*depth), "\n"
+	}
+
+	//	on its own line the spaces around text are layout, which is ours to decide
+	if e.isText() {
+		text := e.escapedText()
+		if !opts.Compact {
+			if text = strings.TrimSpace(text); text == "" {
+				return
+			}
+		}
+		sb.WriteString(indent + text + newline)
+		return
+	}
+
+	//	void elements have no closing tag and cant hold anything
+	if voidElements[e.name] {
+		sb.WriteString(indent + e.openTag(opts.XHTML) + newline)
+		return
+	}
+
+	children := e.children()
+	if len(children) == 0 || opts.Compact || (opts.InlineText && e.onlyText()) {
+		sb.WriteString(indent + e.openTag(false))
+		for _, c := range children {
+			c.render(sb, RenderOptions{Compact: true, XHTML: opts.XHTML}, 0)
+		}
+		sb.WriteString(e.closeTag() + newline)
+		return
+	}
+
+	sb.WriteString(indent + e.openTag(false) + newline)
+	for _, c := range children {
+		c.render(sb, opts, depth+1)
+	}
+	sb.WriteString(indent + e.closeTag() + newline)
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestParseRawText(t *testing.T) {
+	for _, tc := range []struct {
+		src, want string
+	}{
+		//	İ lowercases to more bytes, the end tag has to be found in the original
+		{`<script>var s = "İİ";</script>`, `<script>var s = "İİ";</script>`},
+		{`<SCRIPT>a</scripts></Script>`, `<script>a</scripts></script>`},
+		{`<style>p { color: red }</STYLE>`, `<style>p { color: red }</style>`},
+	} {
+		e, err := ParseHtml(tc.src)
+		if err != nil {
+			t.Errorf("%s: %v", tc.src, err)
+			continue
+		}
+		if got := e.Render(RenderOptions{Compact: true}); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.src, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import "testing"
+
+func TestRenderModes(t *testing.T) {
+	b := NewHtmlBuilder("form")
+	b.Attr("action", "/search").
+		Child("label").Text("Query").Child("br").Up().Up().
+		Child("input").Attr("name", "q").Up().
+		Child("div").Class("hint").Up().
+		Child("p").Text("a & b").Child("em").Text("c").Up().Up()
+	root := &b.root
+
+	for _, tc := range []struct {
+		name   string
+		opts   RenderOptions
+		golden string
+	}{
+		{"default", DefaultRenderOptions, `<form action="/search">
+  <label>
+    Query
+    <br>
+  </label>
+  <input name="q">
+  <div class="hint"></div>
+  <p>
+    a &amp; b
+    <em>
+      c
+    </em>
+  </p>
+</form>
+`},
+		{"compact", RenderOptions{Compact: true},
+			`<form action="/search"><label>Query<br></label><input name="q"><div class="hint"></div><p>a &amp; b<em>c</em></p></form>`},
+		{"inline text, indent 4, xhtml", RenderOptions{Indent: 4, InlineText: true, XHTML: true}, `<form action="/search">
+    <label>
+        Query
+        <br />
+    </label>
+    <input name="q" />
+    <div class="hint"></div>
+    <p>
+        a &amp; b
+        <em>c</em>
+    </p>
+</form>
+`},
+		{"no indent", RenderOptions{InlineText: true}, `<form action="/search">
+<label>
+Query
+<br>
+</label>
+<input name="q">
+<div class="hint"></div>
+<p>
+a &amp; b
+<em>c</em>
+</p>
+</form>
+`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := root.Render(tc.opts); got != tc.golden {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.golden)
+			}
+		})
+	}
+}
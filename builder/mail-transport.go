@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// delivers an already built message, from and to are bare addresses
+type Transport interface {
+	Send(from string, to []string, msg []byte) error
+}
+
+// only says what would have been sent
+type printTransport struct{}
+
+func (printTransport) Send(from string, to []string, msg []byte) error {
+	fmt.Printf("Email sent to `%s` by `%s` (%d bytes)\n", strings.Join(to, ", "), from, len(msg))
+	return nil
+}
+
+var DefaultTransport Transport = printTransport{}
+
+type SMTPTransport struct {
+	Addr      string //	host:port
+	Username  string //	AUTH PLAIN is used when set
+	Password  string
+	LocalName string      //	name sent with EHLO, defaults to localhost
+	TLSConfig *tls.Config //	used for STARTTLS, the server name is filled in from Addr
+}
+
+func (t *SMTPTransport) Send(from string, to []string, msg []byte) error {
+	host, _, err := net.SplitHostPort(t.Addr)
+	if err != nil {
+		return err
+	}
+
+	c, err := smtp.Dial(t.Addr)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	localName := t.LocalName
+	if localName == "" {
+		localName = "localhost"
+	}
+	if err := c.Hello(localName); err != nil {
+		return err
+	}
+
+	//	upgrade whenever the server offers it
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		cfg := &tls.Config{}
+		if t.TLSConfig != nil {
+			cfg = t.TLSConfig.Clone()
+		}
+		if cfg.ServerName == "" {
+			cfg.ServerName = host
+		}
+		if err := c.StartTLS(cfg); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+
+	if t.Username != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp server does not support AUTH")
+		}
+		//	PlainAuth refuses to send the password unencrypted, unless the server is local
+		if err := c.Auth(smtp.PlainAuth("", t.Username, t.Password, host)); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := c.Rcpt(addr); err != nil {
+			return fmt.Errorf("rcpt %s: %w", addr, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// slashes and colons cant be in a maildir file name, a colon starts the info part,
+// so the hostname has them written as octal escapes like the maildir spec asks
+var maildirHost = strings.NewReplacer("/", `\057`, ":", `\072`)
+
+// writes every message into a maildir, so other tools can pick them up
+// files are written into tmp and moved into new once complete
+type SpoolTransport struct {
+	Dir string
+}
+
+func (t *SpoolTransport) Send(from string, to []string, msg []byte) error {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(t.Dir, sub), 0o700); err != nil {
+			return err
+		}
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	hostname, _ := os.Hostname()
+	name := fmt.Sprintf("%d.%d_%s.%s", time.Now().Unix(), os.Getpid(), hex.EncodeToString(id), maildirHost.Replace(hostname))
+
+	//	the envelope is kept in headers, maildir has no other place for it
+	buf := bytes.Buffer{}
+	fmt.Fprintf(&buf, "Return-Path: <%s>\r\n", from)
+	for _, addr := range to {
+		fmt.Fprintf(&buf, "Delivered-To: %s\r\n", addr)
+	}
+	buf.Write(msg)
+
+	tmp := filepath.Join(t.Dir, "tmp", name)
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(t.Dir, "new", name))
+}
+
+type SentMail struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// keeps the messages in memory, meant for tests
+type MemoryTransport struct {
+	mu   sync.Mutex
+	sent []SentMail
+}
+
+func (t *MemoryTransport) Send(from string, to []string, msg []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sent = append(t.sent, SentMail{
+		From: from,
+		To:   append([]string(nil), to...),
+		Data: append([]byte(nil), msg...),
+	})
+	return nil
+}
+
+func (t *MemoryTransport) Sent() []SentMail {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]SentMail(nil), t.sent...)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func composeTransportMail(eb *EmailBuilder) {
+	eb.
+		From("Abc <abc@gmail.com>").
+		To("def@yahoo.com").
+		Bcc("boss@gmail.com").
+		Subject("Transports").
+		Body("Hello through every transport")
+}
+
+// the envelope carries the Bcc recipients, the message itself must not name them
+func checkEnvelope(t *testing.T, mail SentMail) {
+	t.Helper()
+	if mail.From != "abc@gmail.com" {
+		t.Errorf("from = %q, want abc@gmail.com", mail.From)
+	}
+	if want := []string{"def@yahoo.com", "boss@gmail.com"}; !slices.Equal(mail.To, want) {
+		t.Errorf("to = %v, want %v", mail.To, want)
+	}
+	if bytes.Contains(mail.Data, []byte("boss@gmail.com")) {
+		t.Errorf("the Bcc recipient is in the message:\n%s", mail.Data)
+	}
+}
+
+// the Date header can differ between two sends and smtp changes the line endings, everything else should match
+func sameBody(a, b []byte) bool {
+	cut := func(msg []byte) []byte {
+		msg = bytes.TrimRight(bytes.ReplaceAll(msg, []byte("\r\n"), []byte("\n")), "\n")
+		if i := bytes.Index(msg, []byte("MIME-Version")); i >= 0 {
+			return msg[i:]
+		}
+		return msg
+	}
+	return bytes.Equal(cut(a), cut(b))
+}
+
+func TestMemoryTransport(t *testing.T) {
+	memory := &MemoryTransport{}
+	if err := SendMailWith(memory, composeTransportMail); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := memory.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(sent))
+	}
+	checkEnvelope(t, sent[0])
+}
+
+func TestSpoolTransport(t *testing.T) {
+	dir := t.TempDir()
+	if err := SendMailWith(&SpoolTransport{Dir: dir}, composeTransportMail); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := os.ReadDir(filepath.Join(dir, "new"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("%d files in new, want 1", len(files))
+	}
+	if name := files[0].Name(); strings.ContainsAny(name, ":/") {
+		t.Errorf("file name %q has a character maildir does not allow", name)
+	}
+	if tmp, _ := os.ReadDir(filepath.Join(dir, "tmp")); len(tmp) != 0 {
+		t.Errorf("%d files left in tmp", len(tmp))
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "new", files[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, header := range []string{"Return-Path: <abc@gmail.com>", "Delivered-To: def@yahoo.com", "Delivered-To: boss@gmail.com"} {
+		if !bytes.Contains(data, []byte(header)) {
+			t.Errorf("spooled message has no %q", header)
+		}
+	}
+}
+
+func startTestSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	server, err := startFakeSMTPServer("abc", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+func TestSMTPTransport(t *testing.T) {
+	server := startTestSMTPServer(t)
+	transport := &SMTPTransport{
+		Addr:      server.Addr(),
+		Username:  "abc",
+		Password:  "secret",
+		TLSConfig: server.ClientTLSConfig(),
+	}
+	if err := SendMailWith(transport, composeTransportMail); err != nil {
+		t.Fatal(err)
+	}
+
+	if !server.UsedTLS() {
+		t.Error("STARTTLS was not used")
+	}
+	received := server.Received()
+	if len(received) != 1 {
+		t.Fatalf("server received %d messages, want 1", len(received))
+	}
+	checkEnvelope(t, received[0])
+
+	//	the same message as the one kept in memory
+	memory := &MemoryTransport{}
+	if err := SendMailWith(memory, composeTransportMail); err != nil {
+		t.Fatal(err)
+	}
+	if !sameBody(received[0].Data, memory.Sent()[0].Data) {
+		t.Errorf("smtp message differs from the one kept in memory:\n%s\n---\n%s", received[0].Data, memory.Sent()[0].Data)
+	}
+}
+
+func TestSMTPTransportWrongPassword(t *testing.T) {
+	server := startTestSMTPServer(t)
+	transport := &SMTPTransport{
+		Addr:      server.Addr(),
+		Username:  "abc",
+		Password:  "wrong",
+		TLSConfig: server.ClientTLSConfig(),
+	}
+
+	err := SendMailWith(transport, composeTransportMail)
+	if err == nil || !strings.Contains(err.Error(), "535") {
+		t.Errorf("err = %v, want the server's 535", err)
+	}
+	if n := len(server.Received()); n != 0 {
+		t.Errorf("server received %d messages with a wrong password", n)
+	}
+}
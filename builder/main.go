@@ -3,6 +3,7 @@ package main
 func main() {
 	// TestStringBuilder()
 	// TestBuilderFacet()
+	TestGeneratedBuilder()
 	// TestBuilderParams()
-	TestFunctionalBuilder()
+	// TestFunctionalBuilder()
 }
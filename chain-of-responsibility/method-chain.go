@@ -1,7 +1,12 @@
 package main
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
 
+// Attack and Defense are the base stats, modifiers never change them
 type Creature struct {
 	Name            string
 	Attack, Defense int
@@ -19,35 +24,103 @@ func NewCreature(name string, attack, defense int) *Creature {
 	}
 }
 
+// the stats that come out at the end of the chain
+type Stats struct {
+	Attack, Defense int
+}
+
 type Modifier interface {
-	Add(m Modifier)
-	Handle()
+	Creature() *Creature
+	Handle(stats *Stats) bool //	false stops the modifiers after it from running
 }
 
-// This struct is mainly used as a root modifier and for other modifiers to inherit Add() function
+// This struct is mainly used for other modifiers to inherit the creature they belong to
 type CreatureModifier struct {
 	creature *Creature
-	next     Modifier //	it's a modifier not creature modifier
 }
 
-// it's a modifier not creature modifier
-func (c *CreatureModifier) Add(m Modifier) {
-	if c.next != nil {
-		c.next.Add(m)
-	} else {
-		c.next = m
+func (c *CreatureModifier) Creature() *Creature {
+	return c.creature
+}
+
+var (
+	ErrForeignModifier = errors.New("modifier belongs to another creature")
+	ErrUnknownModifier = errors.New("modifier is not in the chain")
+)
+
+// chain of modifiers for a single creature
+// it is run from the base stats every time, so modifiers can be removed or reordered at any point
+type CreatureChain struct {
+	creature  *Creature
+	modifiers []Modifier
+}
+
+// a chain without a creature takes no modifiers and handles into zero stats
+func NewCreatureChain(c *Creature) *CreatureChain {
+	return &CreatureChain{creature: c}
+}
+
+func (ch *CreatureChain) Add(m Modifier) error {
+	switch {
+	case m == nil:
+		return fmt.Errorf("%w: the modifier is nil", ErrForeignModifier)
+	case ch.creature == nil:
+		return fmt.Errorf("%w: the chain has no creature", ErrForeignModifier)
+	case m.Creature() == nil:
+		return fmt.Errorf("%w: %s cant take a modifier without a creature", ErrForeignModifier, ch.creature.Name)
+	case m.Creature() != ch.creature:
+		return fmt.Errorf("%w: %s cant take a modifier for %s", ErrForeignModifier, ch.creature.Name, m.Creature().Name)
 	}
+	ch.modifiers = append(ch.modifiers, m)
+	return nil
 }
-func (c *CreatureModifier) Handle() {
-	if c.next != nil {
-		c.next.Handle()
+
+func (ch *CreatureChain) Remove(m Modifier) error {
+	i := slices.Index(ch.modifiers, m)
+	if i < 0 {
+		return ErrUnknownModifier
 	}
+	ch.modifiers = slices.Delete(ch.modifiers, i, i+1)
+	return nil
 }
 
-func NewCreatureModifier(c *Creature) *CreatureModifier {
-	return &CreatureModifier{
-		creature: c,
+// moves the modifier so that it runs at the given position
+func (ch *CreatureChain) Move(m Modifier, position int) error {
+	i := slices.Index(ch.modifiers, m)
+	if i < 0 {
+		return ErrUnknownModifier
+	}
+	if position < 0 || position >= len(ch.modifiers) {
+		return fmt.Errorf("position %d is outside of the chain of %d modifiers", position, len(ch.modifiers))
 	}
+	ch.modifiers = slices.Insert(slices.Delete(ch.modifiers, i, i+1), position, m)
+	return nil
+}
+
+func (ch *CreatureChain) Modifiers() []Modifier {
+	return slices.Clone(ch.modifiers)
+}
+
+// runs every modifier in order, starting from the base stats
+func (ch *CreatureChain) Handle() Stats {
+	if ch.creature == nil {
+		return Stats{}
+	}
+	stats := Stats{Attack: ch.creature.Attack, Defense: ch.creature.Defense}
+	for _, m := range ch.modifiers {
+		if !m.Handle(&stats) {
+			break
+		}
+	}
+	return stats
+}
+
+func (ch *CreatureChain) String() string {
+	if ch.creature == nil {
+		return "no creature"
+	}
+	s := ch.Handle()
+	return fmt.Sprintf("%s (%d/%d)", ch.creature.Name, s.Attack, s.Defense)
 }
 
 // double attack modifier definition
@@ -55,10 +128,10 @@ type DoubleAttackModifier struct {
 	CreatureModifier
 }
 
-func (d *DoubleAttackModifier) Handle() {
+func (d *DoubleAttackModifier) Handle(stats *Stats) bool {
 	fmt.Println("Doubling", d.creature.Name, "\b's attack")
-	d.creature.Attack *= 2
-	d.CreatureModifier.Handle()
+	stats.Attack *= 2
+	return true
 }
 
 func NewDoubleAttackModifier(c *Creature) *DoubleAttackModifier {
@@ -74,10 +147,10 @@ type TripleDefenseModifier struct {
 	CreatureModifier
 }
 
-func (t *TripleDefenseModifier) Handle() {
+func (t *TripleDefenseModifier) Handle(stats *Stats) bool {
 	fmt.Println("Tripling", t.creature.Name, "\b's defense")
-	t.creature.Defense *= 3
-	t.CreatureModifier.Handle()
+	stats.Defense *= 3
+	return true
 }
 
 func NewTripleDefenseModifer(c *Creature) *TripleDefenseModifier {
@@ -88,13 +161,13 @@ func NewTripleDefenseModifer(c *Creature) *TripleDefenseModifier {
 	}
 }
 
-// no bonuses modifier
+// no bonuses modifier, nothing after it gets applied while it is in the chain
 type NoBonusModifier struct {
 	CreatureModifier
 }
 
-func (n *NoBonusModifier) Handle() {
-	//	empty
+func (n *NoBonusModifier) Handle(stats *Stats) bool {
+	return false
 }
 
 func NewNoBonusModifier(c *Creature) *NoBonusModifier {
@@ -109,20 +182,36 @@ func TestMethodChain() {
 	goblin := NewCreature("Goblin", 1, 1)
 	fmt.Println(goblin.String())
 
-	root := NewCreatureModifier(goblin)
+	root := NewCreatureChain(goblin)
 	root.Add(NewDoubleAttackModifier(goblin))
 	root.Add(NewTripleDefenseModifer(goblin))
 	root.Add(NewDoubleAttackModifier(goblin))
 
-	root.Add(NewNoBonusModifier(goblin))
-	root.Add(NewTripleDefenseModifer(goblin)) //	wont get applied, also no other modifiers can be added at all
-	root.Handle()
-	fmt.Println(goblin.String())
-}
+	noBonus := NewNoBonusModifier(goblin)
+	root.Add(noBonus)
+	root.Add(NewTripleDefenseModifer(goblin)) //	wont get applied while noBonus is there
+	fmt.Println(root.String())
+
+	//	the base stats are untouched, so running it again gives the same result
+	fmt.Println(goblin.String(), "->", root.String())
+
+	//	taking the no bonus modifier out lets the last one through again
+	root.Remove(noBonus)
+	fmt.Println(root.String())
+
+	//	moving it to the front blocks everything
+	root.Add(noBonus)
+	root.Move(noBonus, 0)
+	fmt.Println(root.String())
 
-//	Note that there's a bug here.
-//	Let's say we define a new creature "elf" and if we pass it as `root.Add(NewDoubleAttackModifer(elf))`,
-//	there will be no errors thrown and there would be no changes in goblin
-//	I think the modifier is defined on a global level and not on creature level, so at any point of time,
-//	there would be tons of modifier in a singly linked list which will be specifying what bonuses which
-//	all characters have
+	//	a modifier for another creature is rejected
+	elf := NewCreature("Elf", 2, 2)
+	if err := root.Add(NewDoubleAttackModifier(elf)); err != nil {
+		fmt.Println(err)
+	}
+
+	//	so is anything without a creature
+	fmt.Println(root.Add(NewDoubleAttackModifier(nil)))
+	fmt.Println(root.Add(nil))
+	fmt.Println(NewCreatureChain(nil).Add(NewDoubleAttackModifier(nil)))
+}
@@ -1,3 +1,3 @@
 module github.com/resonantchaos22/go-design-patterns-course
 
-go 1.22.2
+go 1.23